@@ -1,8 +1,12 @@
 package acl
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/alipourhabibi/abacl-go/audit"
 	"github.com/alipourhabibi/abacl-go/driver"
 	"github.com/alipourhabibi/abacl-go/grant"
 	"github.com/alipourhabibi/abacl-go/permission"
@@ -14,12 +18,71 @@ type Options struct {
 	// Strict mode requires exact scope matching
 	// If false, scopes are matched with wildcards
 	Strict bool
+
+	// Merge selects how Fields and Filters of several matched policies are combined
+	// Defaults to grant.MergeUnion
+	Merge grant.Merge
+
+	// Logger receives every authorization decision, nil disables logging
+	Logger audit.Logger
+
+	// Relations evaluates the relations required by policies, e.g. a *rebac.Checker
+	// Policies with a Relation never match without it
+	Relations RelationChecker
+
+	// Obligations makes granted checks fail when an obligation of the matched
+	// policies cannot be fulfilled, e.g. an *obligation.Registry without its handler
+	Obligations ObligationHandlers
+
+	// Roles expands the subjects of every check with the roles they inherit
+	Roles RoleResolver
+}
+
+// ObligationHandlers reports the obligations no handler can fulfill
+type ObligationHandlers interface {
+	Unhandled(obligations []policy.Obligation) error
+}
+
+// RelationChecker reports whether a subject holds a relation on an object
+type RelationChecker interface {
+	Check(object, relation, subject string) (bool, error)
+}
+
+// Request describes a single authorization check
+type Request struct {
+	Subjects []string
+	Action   string
+	Object   string
+
+	// Strict requires exact scope matching, Check sets it from Options
+	Strict bool
+
+	// Metadata is passed to the decision logger, e.g. request id or client address
+	Metadata map[string]any
+
+	// Attributes of the subject, compared to the "{param}" segments captured
+	// from the object path by policies such as "org/{orgId}/project/*"
+	Attributes map[string]any
+
+	// Principal and Resource identify the subject and object instances for
+	// policies requiring a relation, e.g. "user:alice" and "document:9"
+	Principal string
+	Resource  string
+
+	// Revision evaluates the check against a past revision of the policies
+	// 0 uses the latest policies, others require a driver.Versioned driver
+	Revision int64
+
+	// Context is passed to drivers implementing driver.ContextFinder, nil means context.Background
+	Context context.Context
 }
 
 // AccessControl manages policy-based access control
 type AccessControl struct {
 	opts   Options
 	driver driver.Driver
+	tenant string
+	author string
 }
 
 // New creates a new AccessControl instance with the given policies
@@ -43,30 +106,103 @@ func New(policies []policy.Policy, opts Options, drv driver.Driver) (*AccessCont
 	return ac, nil
 }
 
+// Options returns the options the access control was created with
+func (ac *AccessControl) Options() Options {
+	return ac.opts
+}
+
+// Tenant returns a view of the access control scoped to a single tenant
+// The view shares the driver, its checks only match the tenant's policies
+// and the platform-wide policies of the global tenant
+func (ac *AccessControl) Tenant(name string) *AccessControl {
+	view := *ac
+	view.tenant = name
+	return &view
+}
+
+// As returns a view of the access control recording author on the revisions
+// its mutations create, when the driver implements driver.Versioned
+func (ac *AccessControl) As(author string) *AccessControl {
+	view := *ac
+	view.author = author
+	return &view
+}
+
 // Add adds or updates a policy
 func (ac *AccessControl) Add(p policy.Policy) error {
+	p, err := ac.own(p)
+	if err != nil {
+		return err
+	}
+	if v, ok := ac.driver.(driver.Versioned); ok {
+		return v.SetAs(ac.author, p)
+	}
 	return ac.driver.Set(p)
 }
 
 // Remove deletes a policy
 func (ac *AccessControl) Remove(p policy.Policy) error {
+	p, err := ac.own(p)
+	if err != nil {
+		return err
+	}
+	if v, ok := ac.driver.(driver.Versioned); ok {
+		return v.DeleteAs(ac.author, p.Key())
+	}
 	return ac.driver.Delete(p.Key())
 }
 
 // Exists checks if a policy exists
 func (ac *AccessControl) Exists(p policy.Policy) bool {
+	p, err := ac.own(p)
+	if err != nil {
+		return false
+	}
 	return ac.driver.Exists(p.Key())
 }
 
 // Clear removes all policies
+// A tenant view only removes the policies of its tenant
 func (ac *AccessControl) Clear() error {
+	v, versioned := ac.driver.(driver.Versioned)
+
+	if ac.tenant != policy.GlobalTenant {
+		if versioned {
+			return v.ClearTenantAs(ac.author, ac.tenant)
+		}
+		return driver.ClearTenant(ac.driver, ac.tenant)
+	}
+	if versioned {
+		return v.ClearAs(ac.author)
+	}
 	return ac.driver.Clear()
 }
 
+// own assigns the view's tenant to a policy
+// The global view may manage policies of any tenant, a tenant view only its own
+func (ac *AccessControl) own(p policy.Policy) (policy.Policy, error) {
+	if ac.tenant == policy.GlobalTenant {
+		return p, nil
+	}
+	if p.Tenant == policy.GlobalTenant {
+		p.Tenant = ac.tenant
+	}
+	if p.Tenant != ac.tenant {
+		return p, fmt.Errorf("policy belongs to tenant %q, not %q", p.Tenant, ac.tenant)
+	}
+	return p, nil
+}
+
 // Get searches for policies matching the given criteria
 // This uses your original strictify logic
 func (ac *AccessControl) Get(strict bool, pol policy.Policy) ([]policy.Policy, error) {
+	return ac.get(context.Background(), strict, pol, 0)
+}
+
+// get is like Get but searches the policies as of a revision, 0 being the latest
+func (ac *AccessControl) get(ctx context.Context, strict bool, pol policy.Policy, rev int64) ([]policy.Policy, error) {
 	var searchPolicy policy.Policy
+	pol.Tenant = ac.tenant
 
 	if !strict {
 		// Use strictify to add regex wildcards
@@ -75,7 +211,33 @@ func (ac *AccessControl) Get(strict bool, pol policy.Policy) ([]policy.Policy, e
 		searchPolicy = pol
 	}
 
-	return ac.driver.Find(searchPolicy)
+	policies, err := ac.find(ctx, rev, searchPolicy)
+	if err != nil || ac.tenant == policy.GlobalTenant {
+		return policies, err
+	}
+
+	// Tenants inherit the platform-wide policies
+	searchPolicy.Tenant = policy.GlobalTenant
+	global, err := ac.find(ctx, rev, searchPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return append(policies, global...), nil
+}
+
+func (ac *AccessControl) find(ctx context.Context, rev int64, pattern policy.Policy) ([]policy.Policy, error) {
+	if rev == 0 {
+		if f, ok := ac.driver.(driver.ContextFinder); ok {
+			return f.FindContext(ctx, pattern)
+		}
+		return ac.driver.Find(pattern)
+	}
+
+	v, err := ac.versioned()
+	if err != nil {
+		return nil, err
+	}
+	return v.FindAt(rev, pattern)
 }
 
 // Check evaluates if the given subjects have permission to perform an action on an object
@@ -86,14 +248,45 @@ func (ac *AccessControl) Check(subjects []string, action, object string) (*permi
 
 // CheckWithOptions is like Check but allows overriding the strict mode
 func (ac *AccessControl) CheckWithOptions(subjects []string, action, object string, strict bool) (*permission.Permission, error) {
-	if len(subjects) == 0 {
-		return nil, fmt.Errorf("at least one subject is required")
+	return ac.CheckRequest(Request{
+		Subjects: subjects,
+		Action:   action,
+		Object:   object,
+		Strict:   strict,
+	})
+}
+
+// CheckRequest is like Check but takes every parameter of the check in a Request
+// Failed checks are logged as well, with their error
+func (ac *AccessControl) CheckRequest(req Request) (*permission.Permission, error) {
+	start := time.Now()
+	perm, matched, err := ac.check(req)
+	if ac.opts.Logger != nil {
+		ac.logDecision(req, matched, perm, err, start)
 	}
-	if action == "" {
-		return nil, fmt.Errorf("action cannot be empty")
+	return perm, err
+}
+
+// check evaluates a request, the matched policies are returned even when it fails
+func (ac *AccessControl) check(req Request) (*permission.Permission, []policy.Policy, error) {
+	if len(req.Subjects) == 0 {
+		return nil, nil, fmt.Errorf("at least one subject is required")
 	}
-	if object == "" {
-		return nil, fmt.Errorf("object cannot be empty")
+	if req.Action == "" {
+		return nil, nil, fmt.Errorf("action cannot be empty")
+	}
+	if req.Object == "" {
+		return nil, nil, fmt.Errorf("object cannot be empty")
+	}
+
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	subjects := req.Subjects
+	if ac.opts.Roles != nil {
+		subjects = ac.opts.Roles.Expand(subjects)
 	}
 
 	// Generate search keys for each subject (your original logic)
@@ -101,30 +294,115 @@ func (ac *AccessControl) CheckWithOptions(subjects []string, action, object stri
 	for _, subject := range subjects {
 		pol := policy.Policy{
 			Subject: subject,
-			Object:  object,
-			Action:  action,
+			Object:  req.Object,
+			Action:  req.Action,
 		}
 
-		policies, err := ac.Get(strict, pol)
+		policies, err := ac.get(ctx, req.Strict, pol, req.Revision)
 		if err != nil {
-			return nil, fmt.Errorf("query failed for subject %s: %w", subject, err)
+			return nil, allPolicies, fmt.Errorf("query failed for subject %s: %w", subject, err)
+		}
+		for _, p := range policies {
+			if !p.MatchAttributes(req.Object, req.Attributes) {
+				continue
+			}
+			ok, err := ac.related(req, p)
+			if err != nil {
+				return nil, allPolicies, fmt.Errorf("relation check failed for subject %s: %w", subject, err)
+			}
+			if ok {
+				allPolicies = append(allPolicies, p)
+			}
 		}
-		allPolicies = append(allPolicies, policies...)
 	}
 
 	// Create grant from matched policies
 	granted := len(allPolicies) > 0
-	g, err := grant.New(allPolicies, strict)
+	if granted && ac.opts.Obligations != nil {
+		if err := ac.opts.Obligations.Unhandled(policy.Obligations(allPolicies)); err != nil {
+			return nil, allPolicies, fmt.Errorf("granted with unenforceable obligations: %w", err)
+		}
+	}
+	g, err := grant.NewWithOptions(allPolicies, grant.Options{Strict: req.Strict, Merge: ac.opts.Merge})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create grant: %w", err)
+		return nil, allPolicies, fmt.Errorf("failed to create grant: %w", err)
 	}
 
-	return permission.New(granted, g), nil
+	return permission.New(granted, g), allPolicies, nil
+}
+
+// related reports whether the request satisfies the relation required by a policy
+func (ac *AccessControl) related(req Request, p policy.Policy) (bool, error) {
+	if p.Relation == "" {
+		return true, nil
+	}
+	if ac.opts.Relations == nil || req.Principal == "" || req.Resource == "" {
+		return false, nil
+	}
+	return ac.opts.Relations.Check(req.Resource, p.Relation, req.Principal)
+}
+
+// logDecision sends the outcome of a check to the decision logger
+// Subjects and metadata are copied, loggers may keep the decision after the check returns
+func (ac *AccessControl) logDecision(req Request, matched []policy.Policy, perm *permission.Permission, err error, start time.Time) {
+	keys := make([]string, 0, len(matched))
+	for _, p := range matched {
+		keys = append(keys, p.Key())
+	}
+
+	d := audit.Decision{
+		Time:     start,
+		Tenant:   ac.tenant,
+		Subjects: slices.Clone(req.Subjects),
+		Action:   req.Action,
+		Object:   req.Object,
+		Granted:  perm != nil && perm.Granted(),
+		Policies: keys,
+		Latency:  time.Since(start),
+		Metadata: copyMetadata(req.Metadata),
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	ac.opts.Logger.Log(d)
+}
+
+// copyMetadata deep copies the maps and lists of request metadata
+func copyMetadata(md map[string]any) map[string]any {
+	if md == nil {
+		return nil
+	}
+	out := make(map[string]any, len(md))
+	for k, v := range md {
+		out[k] = copyValue(v)
+	}
+	return out
+}
+
+func copyValue(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		return copyMetadata(vv)
+	case []any:
+		out := make([]any, len(vv))
+		for i, item := range vv {
+			out[i] = copyValue(item)
+		}
+		return out
+	case []string:
+		return slices.Clone(vv)
+	}
+	return v
 }
 
 // ListAll returns all stored policies
+// A tenant view only lists the policies of its tenant
 func (ac *AccessControl) ListAll() ([]policy.Policy, error) {
 	keys := ac.driver.List()
+	if ac.tenant != policy.GlobalTenant {
+		keys = driver.ListTenant(ac.driver, ac.tenant)
+	}
+
 	policies := make([]policy.Policy, 0, len(keys))
 
 	for _, key := range keys {
@@ -135,3 +413,117 @@ func (ac *AccessControl) ListAll() ([]policy.Policy, error) {
 
 	return policies, nil
 }
+
+// ListPage returns a page of the stored policies, sorted by key
+// A tenant view only lists the policies of its tenant
+func (ac *AccessControl) ListPage(req driver.PageRequest) (driver.Page[policy.Policy], error) {
+	var (
+		keys driver.Page[string]
+		err  error
+	)
+	if ac.tenant != policy.GlobalTenant {
+		keys, err = driver.ListTenantPage(ac.driver, ac.tenant, req)
+	} else {
+		keys, err = driver.ListPage(ac.driver, req)
+	}
+	if err != nil {
+		return driver.Page[policy.Policy]{}, err
+	}
+
+	page := driver.Page[policy.Policy]{Items: make([]policy.Policy, 0, len(keys.Items)), Next: keys.Next}
+	for _, key := range keys.Items {
+		if p, ok := ac.driver.Get(key); ok {
+			page.Items = append(page.Items, p)
+		}
+	}
+	return page, nil
+}
+
+// Revisions lists the history of the stored policies, oldest first
+func (ac *AccessControl) Revisions() ([]driver.Revision, error) {
+	v, err := ac.versioned()
+	if err != nil {
+		return nil, err
+	}
+	return v.Revisions(), nil
+}
+
+// Snapshot returns the policies as of a revision
+// A tenant view only returns the policies of its tenant
+func (ac *AccessControl) Snapshot(rev int64) ([]policy.Policy, error) {
+	v, err := ac.versioned()
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := v.Snapshot(rev)
+	if err != nil || ac.tenant == policy.GlobalTenant {
+		return policies, err
+	}
+
+	owned := []policy.Policy{}
+	for _, p := range policies {
+		if p.Tenant == ac.tenant {
+			owned = append(owned, p)
+		}
+	}
+	return owned, nil
+}
+
+// Diff compares the policies of two revisions
+func (ac *AccessControl) Diff(from, to int64) (driver.Diff, error) {
+	before, err := ac.Snapshot(from)
+	if err != nil {
+		return driver.Diff{}, err
+	}
+	after, err := ac.Snapshot(to)
+	if err != nil {
+		return driver.Diff{}, err
+	}
+	return driver.Compare(before, after), nil
+}
+
+// Rollback restores the policies of a revision
+// A tenant view only restores the policies of its tenant
+func (ac *AccessControl) Rollback(rev int64) error {
+	v, err := ac.versioned()
+	if err != nil {
+		return err
+	}
+	if ac.tenant == policy.GlobalTenant {
+		return v.Rollback(ac.author, rev)
+	}
+	return v.RollbackTenant(ac.author, ac.tenant, rev)
+}
+
+func (ac *AccessControl) versioned() (driver.Versioned, error) {
+	v, ok := ac.driver.(driver.Versioned)
+	if !ok {
+		return nil, fmt.Errorf("driver does not support versioning")
+	}
+	return v, nil
+}
+
+// Fork copies every stored policy, of all tenants, into drv and returns an
+// access control on it with the same options and view
+// The fork does not log decisions, changes to it never reach the original driver
+func (ac *AccessControl) Fork(drv driver.Driver) (*AccessControl, error) {
+	if drv == nil {
+		return nil, fmt.Errorf("driver cannot be nil")
+	}
+
+	for _, key := range ac.driver.List() {
+		p, ok := ac.driver.Get(key)
+		if !ok {
+			continue
+		}
+		if err := drv.Set(p); err != nil {
+			return nil, fmt.Errorf("failed to copy policy: %w", err)
+		}
+	}
+
+	fork := *ac
+	fork.driver = drv
+	fork.opts.Logger = nil
+	return &fork, nil
+}
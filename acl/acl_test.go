@@ -3,8 +3,13 @@ package acl
 import (
 	"testing"
 
+	"github.com/alipourhabibi/abacl-go/audit"
+	"github.com/alipourhabibi/abacl-go/driver"
 	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/permission"
 	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/alipourhabibi/abacl-go/rebac"
+	rebacmem "github.com/alipourhabibi/abacl-go/rebac/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -202,6 +207,36 @@ func TestMemoryDriver_Clear(t *testing.T) {
 	assert.True(t, drv.Exists(p.Key()))
 }
 
+func TestMemoryDriver_History(t *testing.T) {
+	drv := memory.NewMemoryDriverWithOptions(memory.Options{MaxRevisions: 3, CheckpointEvery: 2})
+
+	objects := []string{"a", "b", "c", "d", "e"}
+	for _, o := range objects {
+		require.NoError(t, drv.Set(policy.Policy{Subject: "user", Action: "read", Object: o}))
+	}
+
+	revs := drv.Revisions()
+	require.Len(t, revs, 3)
+	assert.Equal(t, int64(3), revs[0].ID)
+	assert.Equal(t, int64(5), revs[2].ID)
+
+	_, err := drv.Snapshot(1)
+	assert.ErrorContains(t, err, "no longer retained")
+
+	// Revisions before, at and after a checkpoint replay to the same policies
+	for rev := int64(2); rev <= 5; rev++ {
+		snap, err := drv.Snapshot(rev)
+		require.NoError(t, err)
+		assert.Len(t, snap, int(rev))
+	}
+
+	require.NoError(t, drv.Rollback("", 2))
+	snap, err := drv.Snapshot(6)
+	require.NoError(t, err)
+	assert.Len(t, snap, 2)
+	assert.Len(t, drv.List(), 2)
+}
+
 func TestAccessControl_Get(t *testing.T) {
 	drv := memory.NewMemoryDriver()
 	opts := Options{Strict: false}
@@ -250,6 +285,394 @@ func TestAccessControl_Get(t *testing.T) {
 	})
 }
 
+func TestAccessControl_Tenants(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New(nil, Options{Strict: false}, drv)
+	require.NoError(t, err)
+
+	acme := ac.Tenant("acme")
+	globex := ac.Tenant("globex")
+
+	// Platform-wide default
+	require.NoError(t, ac.Add(policy.Policy{Subject: "user", Action: "read", Object: "help"}))
+	require.NoError(t, acme.Add(policy.Policy{Subject: "user", Action: "read:own", Object: "article"}))
+	require.NoError(t, globex.Add(policy.Policy{Subject: "admin", Action: "delete", Object: "article"}))
+
+	t.Run("tenant matches its own policies", func(t *testing.T) {
+		perm, err := acme.Check([]string{"user"}, "read", "article")
+		require.NoError(t, err)
+		assert.True(t, perm.Granted())
+	})
+
+	t.Run("tenant never matches another tenant", func(t *testing.T) {
+		perm, err := globex.Check([]string{"user"}, "read", "article")
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+
+		perm, err = acme.Check([]string{"admin"}, "delete", "article")
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+	})
+
+	t.Run("global view only matches global policies", func(t *testing.T) {
+		perm, err := ac.Check([]string{"user"}, "read", "article")
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+	})
+
+	t.Run("tenants inherit global policies", func(t *testing.T) {
+		for _, view := range []*AccessControl{acme, globex, ac} {
+			perm, err := view.Check([]string{"user"}, "read", "help")
+			require.NoError(t, err)
+			assert.True(t, perm.Granted())
+		}
+	})
+
+	t.Run("tenant cannot manage another tenant", func(t *testing.T) {
+		err := acme.Add(policy.Policy{Tenant: "globex", Subject: "user", Action: "read", Object: "article"})
+		assert.Error(t, err)
+		assert.False(t, acme.Exists(policy.Policy{Tenant: "globex", Subject: "admin", Action: "delete", Object: "article"}))
+		assert.True(t, globex.Exists(policy.Policy{Subject: "admin", Action: "delete", Object: "article"}))
+	})
+
+	t.Run("global policy cannot take a tenant key", func(t *testing.T) {
+		// Keyed like acme's "user" policy if it were accepted
+		err := ac.Add(policy.Policy{Subject: "acme@user", Action: "read:own", Object: "article"})
+		assert.Error(t, err)
+
+		perm, err := ac.Check([]string{"acme@user"}, "read", "article")
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+	})
+
+	t.Run("list and clear per tenant", func(t *testing.T) {
+		policies, err := acme.ListAll()
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, "acme", policies[0].Tenant)
+
+		all, err := ac.ListAll()
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		require.NoError(t, acme.Clear())
+		policies, err = acme.ListAll()
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+
+		assert.True(t, globex.Exists(policy.Policy{Subject: "admin", Action: "delete", Object: "article"}))
+		assert.True(t, ac.Exists(policy.Policy{Subject: "user", Action: "read", Object: "help"}))
+	})
+}
+
+func TestAccessControl_DecisionLog(t *testing.T) {
+	var decisions []audit.Decision
+	logger := audit.LoggerFunc(func(d audit.Decision) {
+		decisions = append(decisions, d)
+	})
+
+	policies := []policy.Policy{
+		{Subject: "user", Action: "read:own", Object: "article"},
+		{Subject: "user", Action: "read:shared", Object: "article"},
+	}
+	ac, err := New(policies, Options{Logger: logger}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	subjects := []string{"user", "guest"}
+	metadata := map[string]any{"requestId": "abc", "headers": map[string]any{"ip": "10.0.0.1"}}
+	_, err = ac.CheckRequest(Request{
+		Subjects: subjects,
+		Action:   "read",
+		Object:   "article",
+		Metadata: metadata,
+	})
+	require.NoError(t, err)
+
+	// Reusing the request after the check does not change the logged decision
+	subjects[0] = "admin"
+	metadata["requestId"] = "def"
+	metadata["headers"].(map[string]any)["ip"] = "10.0.0.2"
+
+	_, err = ac.Tenant("acme").Check([]string{"guest"}, "delete", "article")
+	require.NoError(t, err)
+
+	_, err = ac.Check(nil, "read", "article")
+	require.Error(t, err)
+
+	require.Len(t, decisions, 3)
+
+	d := decisions[0]
+	assert.Equal(t, []string{"user", "guest"}, d.Subjects)
+	assert.Equal(t, "read", d.Action)
+	assert.Equal(t, "article", d.Object)
+	assert.True(t, d.Granted)
+	assert.ElementsMatch(t, []string{"user:NULL:read:own:article:ANY", "user:NULL:read:shared:article:ANY"}, d.Policies)
+	assert.Equal(t, "abc", d.Metadata["requestId"])
+	assert.Equal(t, "10.0.0.1", d.Metadata["headers"].(map[string]any)["ip"])
+	assert.False(t, d.Time.IsZero())
+	assert.Empty(t, d.Error)
+
+	d = decisions[1]
+	assert.False(t, d.Granted)
+	assert.Equal(t, "acme", d.Tenant)
+	assert.Empty(t, d.Policies)
+
+	d = decisions[2]
+	assert.False(t, d.Granted)
+	assert.Equal(t, "at least one subject is required", d.Error)
+}
+
+func TestAccessControl_Versioning(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New(nil, Options{Strict: true}, drv)
+	require.NoError(t, err)
+
+	read := policy.Policy{Subject: "user", Action: "read", Object: "article"}
+	write := policy.Policy{Subject: "user", Action: "write", Object: "article"}
+	restricted := policy.Policy{Subject: "user", Action: "read", Object: "article", Fields: []string{"title"}}
+
+	alice := ac.As("alice")
+	require.NoError(t, alice.Add(read))              // rev 1
+	require.NoError(t, alice.Add(write))             // rev 2
+	require.NoError(t, ac.As("bob").Remove(write))   // rev 3
+	require.NoError(t, ac.As("bob").Add(restricted)) // rev 4
+	require.NoError(t, ac.Tenant("acme").Add(write)) // rev 5
+	require.NoError(t, ac.As("carol").Clear())       // rev 6
+
+	t.Run("revisions", func(t *testing.T) {
+		revs, err := ac.Revisions()
+		require.NoError(t, err)
+		require.Len(t, revs, 6)
+
+		assert.Equal(t, int64(1), revs[0].ID)
+		assert.Equal(t, "alice", revs[0].Author)
+		assert.Equal(t, driver.OpSet, revs[0].Op)
+		assert.Equal(t, read.Key(), revs[0].Key)
+		assert.False(t, revs[0].Time.IsZero())
+
+		assert.Equal(t, "bob", revs[2].Author)
+		assert.Equal(t, driver.OpDelete, revs[2].Op)
+		assert.Equal(t, "", revs[4].Author)
+		assert.Equal(t, driver.OpClear, revs[5].Op)
+		assert.Equal(t, "carol", revs[5].Author)
+	})
+
+	t.Run("snapshots", func(t *testing.T) {
+		snap, err := ac.Snapshot(2)
+		require.NoError(t, err)
+		assert.Len(t, snap, 2)
+
+		snap, err = ac.Snapshot(6)
+		require.NoError(t, err)
+		assert.Empty(t, snap)
+
+		snap, err = ac.Tenant("acme").Snapshot(5)
+		require.NoError(t, err)
+		require.Len(t, snap, 1)
+		assert.Equal(t, "acme", snap[0].Tenant)
+
+		_, err = ac.Snapshot(42)
+		assert.Error(t, err)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		diff, err := ac.Diff(2, 4)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Added)
+		assert.Equal(t, []policy.Policy{write}, diff.Removed)
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, read, diff.Changed[0].Before)
+		assert.Equal(t, restricted, diff.Changed[0].After)
+
+		diff, err = ac.Diff(4, 4)
+		require.NoError(t, err)
+		assert.True(t, diff.Empty())
+	})
+
+	t.Run("check against a past revision", func(t *testing.T) {
+		perm, err := ac.Check([]string{"user"}, "write", "article")
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+
+		perm, err = ac.CheckRequest(Request{Subjects: []string{"user"}, Action: "write", Object: "article", Strict: true, Revision: 2})
+		require.NoError(t, err)
+		assert.True(t, perm.Granted())
+
+		perm, err = ac.CheckRequest(Request{Subjects: []string{"user"}, Action: "write", Object: "article", Strict: true, Revision: 3})
+		require.NoError(t, err)
+		assert.False(t, perm.Granted())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		require.NoError(t, ac.As("dave").Rollback(4))
+		assert.True(t, ac.Exists(restricted))
+		assert.False(t, ac.Exists(write))
+
+		revs, err := ac.Revisions()
+		require.NoError(t, err)
+		last := revs[len(revs)-1]
+		assert.Equal(t, driver.OpRollback, last.Op)
+		assert.Equal(t, int64(4), last.Target)
+		assert.Equal(t, "dave", last.Author)
+
+		// Rolling back a tenant only restores that tenant's policies, in one revision
+		before := len(drv.Revisions())
+		require.NoError(t, ac.Tenant("acme").Rollback(5))
+		assert.True(t, ac.Tenant("acme").Exists(write))
+		assert.True(t, ac.Exists(restricted))
+
+		revs, err = ac.Revisions()
+		require.NoError(t, err)
+		require.Len(t, revs, before+1)
+		last = revs[len(revs)-1]
+		assert.Equal(t, driver.OpRollback, last.Op)
+		assert.Equal(t, "acme", last.Tenant)
+	})
+}
+
+func TestAccessControl_Wildcards(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New([]policy.Policy{
+		{Subject: "admin", Action: "*", Object: "*"},
+		{Subject: "*", Action: "read", Object: "public/*"},
+		{Subject: "analyst", Action: "read", Object: "reports/*", Fields: []string{"*", "!salary"}},
+		{Subject: "analyst", Action: "read", Object: "reports/payroll", Fields: []string{"total"}},
+		{Subject: "auditor", Action: "read", Object: "billing.*"},
+	}, Options{}, drv)
+	require.NoError(t, err)
+
+	check := func(subject, action, object string) *permission.Permission {
+		t.Helper()
+		perm, err := ac.Check([]string{subject}, action, object)
+		require.NoError(t, err)
+		return perm
+	}
+
+	assert.True(t, check("admin", "delete", "invoice").Granted())
+	assert.True(t, check("guest", "read", "public/faq").Granted())
+	assert.False(t, check("guest", "read", "public/docs/faq").Granted(), "'*' does not cross '/'")
+	assert.False(t, check("guest", "write", "public/faq").Granted())
+	assert.True(t, check("auditor", "read", "billing.invoice").Granted())
+	assert.False(t, check("auditor", "read", "billingXinvoice").Granted(), "globs are not regular expressions")
+
+	data := map[string]any{"total": 10, "salary": 5, "name": "q1"}
+
+	// The glob applies to reports without an exact policy
+	out, err := check("analyst", "read", "reports/q1").Field(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"total": 10, "name": "q1"}, out)
+
+	// The exact policy takes precedence over the glob
+	perm := check("analyst", "read", "reports/payroll")
+	assert.Len(t, perm.Grant().Policies(), 1)
+	out, err = perm.Field(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"total": 10}, out)
+
+	assert.Error(t, ac.Add(policy.Policy{Subject: "admin", Action: "read", Object: "report(s)?*"}))
+}
+
+func TestAccessControl_ResourcePaths(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New([]policy.Policy{
+		{Subject: "member", Action: "read", Object: "org/{orgId}/project/*"},
+		{Subject: "owner", Action: "delete", Object: "org/{orgId}", Cascade: true},
+		{Subject: "auditor", Action: "read", Object: "org/**/document/*"},
+	}, Options{}, drv)
+	require.NoError(t, err)
+
+	check := func(subject, action, object string, attrs map[string]any) bool {
+		t.Helper()
+		perm, err := ac.CheckRequest(Request{
+			Subjects:   []string{subject},
+			Action:     action,
+			Object:     object,
+			Attributes: attrs,
+		})
+		require.NoError(t, err)
+		return perm.Granted()
+	}
+
+	org42 := map[string]any{"orgId": "42"}
+
+	assert.True(t, check("member", "read", "org/42/project/7", org42))
+	assert.False(t, check("member", "read", "org/43/project/7", org42), "captured orgId must equal the attribute")
+	assert.False(t, check("member", "read", "org/42/project/7", nil))
+	assert.False(t, check("member", "read", "org/42/project/7/document/9", org42), "'*' matches a single segment")
+
+	assert.True(t, check("owner", "delete", "org/42", org42))
+	assert.True(t, check("owner", "delete", "org/42/project/7/document/9", org42), "cascades to children")
+	assert.False(t, check("owner", "delete", "org/43/project/7", org42))
+
+	assert.True(t, check("auditor", "read", "org/42/project/7/document/9", nil))
+	assert.False(t, check("auditor", "read", "org/42/project/7", nil))
+}
+
+func TestAccessControl_NestedScopes(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New([]policy.Policy{
+		{Subject: "user", Action: "read:own", Object: "article"},
+		{Subject: "user", Action: "read:own:draft", Object: "article"},
+		{Subject: "user", Action: "read:shared:draft", Object: "article:published:featured"},
+	}, Options{}, drv)
+	require.NoError(t, err)
+
+	matched := func(strict bool, action, object string) int {
+		t.Helper()
+		perm, err := ac.CheckWithOptions([]string{"user"}, action, object, strict)
+		require.NoError(t, err)
+		return len(perm.Grant().Policies())
+	}
+
+	assert.Equal(t, 3, matched(false, "read", "article"), "the last level is relaxed at any depth")
+	assert.Equal(t, 2, matched(false, "read:own:published", "article"), "read:own and its nested scopes")
+	assert.Equal(t, 1, matched(false, "read:shared:draft", "article:published:featured"))
+	assert.Equal(t, 0, matched(false, "read:shared:draft", "article:archived:featured"))
+
+	assert.Equal(t, 1, matched(true, "read:own:draft", "article"))
+	assert.Equal(t, 0, matched(true, "read:own:published", "article"))
+}
+
+func TestAccessControl_Relations(t *testing.T) {
+	store := rebacmem.NewMemoryStore()
+	require.NoError(t, store.Write(
+		rebac.Tuple{Object: "folder:3", Relation: "viewer", Subject: "user:bob"},
+		rebac.Tuple{Object: "document:9", Relation: "parent", Subject: "folder:3"},
+	))
+	checker := rebac.New(store, rebac.Schema{
+		"document": {"viewer": {Parents: []rebac.Parent{{Relation: "parent", Computed: "viewer"}}}},
+	})
+
+	drv := memory.NewMemoryDriver()
+	ac, err := New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "document", Relation: "viewer"},
+		{Subject: "admin", Action: "read", Object: "document"},
+	}, Options{Relations: checker}, drv)
+	require.NoError(t, err)
+
+	check := func(subjects []string, principal string) bool {
+		t.Helper()
+		perm, err := ac.CheckRequest(Request{
+			Subjects:  subjects,
+			Action:    "read",
+			Object:    "document",
+			Principal: principal,
+			Resource:  "document:9",
+		})
+		require.NoError(t, err)
+		return perm.Granted()
+	}
+
+	assert.True(t, check([]string{"user"}, "user:bob"), "viewer of the document's folder")
+	assert.False(t, check([]string{"user"}, "user:alice"))
+	assert.True(t, check([]string{"user", "admin"}, "user:alice"), "role policies still apply")
+
+	// Without a resource the relation cannot hold
+	perm, err := ac.Check([]string{"user"}, "read", "document")
+	require.NoError(t, err)
+	assert.False(t, perm.Granted())
+}
+
 func BenchmarkAccessControl_Check(b *testing.B) {
 	drv := memory.NewMemoryDriver()
 	opts := Options{Strict: false}
@@ -272,3 +695,57 @@ func BenchmarkAccessControl_Check(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestAccessControl_Ordering(t *testing.T) {
+	drv := memory.NewMemoryDriver()
+	ac, err := New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "b"},
+		{Subject: "admin", Action: "read", Object: "c"},
+		{Subject: "user", Action: "read", Object: "a"},
+		{Subject: "guest", Action: "read", Object: "d"},
+		{Subject: "user", Action: "*", Object: "*"},
+	}, Options{}, drv)
+	require.NoError(t, err)
+
+	keys := drv.List()
+	assert.IsIncreasing(t, keys)
+
+	found, err := drv.Find(policy.Policy{Subject: "user", Action: "read", Object: ".*"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "a", found[0].Object)
+	assert.Equal(t, "b", found[1].Object)
+
+	var pages [][]policy.Policy
+	req := driver.PageRequest{Limit: 2}
+	for {
+		page, err := ac.ListPage(req)
+		require.NoError(t, err)
+		pages = append(pages, page.Items)
+		if page.Next == "" {
+			break
+		}
+		req.Cursor = page.Next
+	}
+	require.Len(t, pages, 3)
+	var paged []string
+	for _, items := range pages {
+		for _, p := range items {
+			paged = append(paged, p.Key())
+		}
+	}
+	assert.Equal(t, keys, paged)
+
+	// Cursors point after a key, so a removed key does not shift the next page
+	first, err := ac.ListPage(driver.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.NoError(t, drv.Delete(first.Items[1].Key()))
+	second, err := ac.ListPage(driver.PageRequest{Cursor: first.Next, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, paged[2:4], []string{second.Items[0].Key(), second.Items[1].Key()})
+
+	_, err = ac.ListPage(driver.PageRequest{Cursor: "%%"})
+	assert.Error(t, err)
+	_, err = ac.ListPage(driver.PageRequest{Limit: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package acl
+
+import (
+	"slices"
+	"sync"
+)
+
+// RoleResolver expands the subjects of a check with the roles they inherit
+// Expand is called concurrently by checks, implementations must be safe for it
+type RoleResolver interface {
+	Expand(subjects []string) []string
+}
+
+// Roles maps each subject to the roles it directly inherits
+// Inheritance is transitive: if alice has admin and admin has editor, alice has editor
+// Roles is safe for concurrent use, roles can change while checks expand them
+type Roles struct {
+	mu      sync.RWMutex
+	inherit map[string][]string
+}
+
+// NewRoles creates an empty role hierarchy
+func NewRoles() *Roles {
+	return &Roles{inherit: map[string][]string{}}
+}
+
+// Add makes subject inherit roles
+func (r *Roles) Add(subject string, roles ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Copied on write, slices returned by Inherits are never modified
+	inherited := slices.Clone(r.inherit[subject])
+	for _, role := range roles {
+		if !slices.Contains(inherited, role) {
+			inherited = append(inherited, role)
+		}
+	}
+	r.inherit[subject] = inherited
+}
+
+// Remove removes directly inherited roles of subject
+func (r *Roles) Remove(subject string, roles ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kept []string
+	for _, role := range r.inherit[subject] {
+		if !slices.Contains(roles, role) {
+			kept = append(kept, role)
+		}
+	}
+	if len(kept) == 0 {
+		delete(r.inherit, subject)
+		return
+	}
+	r.inherit[subject] = kept
+}
+
+// Inherits returns the roles subject directly inherits
+func (r *Roles) Inherits(subject string) []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.inherit[subject])
+}
+
+// Map returns a copy of the direct inheritance of every subject
+func (r *Roles) Map() map[string][]string {
+	out := map[string][]string{}
+	if r == nil {
+		return out
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for s, roles := range r.inherit {
+		out[s] = slices.Clone(roles)
+	}
+	return out
+}
+
+// Expand returns the subjects followed by every role they inherit, without duplicates
+func (r *Roles) Expand(subjects []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool, len(subjects))
+	out := make([]string, 0, len(subjects))
+	for _, s := range subjects {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+
+	// Breadth first, so closer roles come first
+	for i := 0; i < len(out); i++ {
+		for _, role := range r.inherit[out[i]] {
+			if !seen[role] {
+				seen[role] = true
+				out = append(out, role)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package audit
+
+import "time"
+
+// Decision records the outcome of a single authorization check
+type Decision struct {
+	Time     time.Time      `json:"time"`
+	Tenant   string         `json:"tenant,omitempty"`
+	Subjects []string       `json:"subjects"`
+	Action   string         `json:"action"`
+	Object   string         `json:"object"`
+	Granted  bool           `json:"granted"`
+	Policies []string       `json:"policies"` // Keys of the matched policies
+	Latency  time.Duration  `json:"latency"`
+	Metadata map[string]any `json:"metadata,omitempty"` // Caller-supplied request metadata
+	Error    string         `json:"error,omitempty"`    // Why the check failed, Granted is then false
+}
+
+// Logger receives authorization decisions
+// Implementations are called on the checking goroutine, wrap slow sinks with NewAsync
+type Logger interface {
+	Log(d Decision)
+}
+
+// LoggerFunc adapts a function to the Logger interface
+type LoggerFunc func(d Decision)
+
+func (f LoggerFunc) Log(d Decision) {
+	f(d)
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+func (r *recorder) Log(d Decision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decisions = append(r.decisions, d)
+}
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.decisions)
+}
+
+func decision() Decision {
+	return Decision{
+		Time:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Subjects: []string{"user"},
+		Action:   "read",
+		Object:   "article",
+		Granted:  true,
+		Policies: []string{"user:NULL:read:ALL:article:ANY"},
+		Latency:  time.Millisecond,
+		Metadata: map[string]any{
+			"requestId": "abc",
+			"headers":   map[string]any{"authorization": "Bearer secret", "accept": "json"},
+		},
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLines(&buf)
+
+	sink.Log(decision())
+	sink.Log(decision())
+	require.NoError(t, sink.Err())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var got Decision
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, []string{"user"}, got.Subjects)
+	assert.Equal(t, "read", got.Action)
+	assert.True(t, got.Granted)
+	assert.Equal(t, "abc", got.Metadata["requestId"])
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSlog(slog.New(slog.NewJSONHandler(&buf, nil)))
+	sink.Log(decision())
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "authorization decision", got["msg"])
+	assert.Equal(t, "read", got["action"])
+	assert.Equal(t, true, got["granted"])
+	assert.Equal(t, "abc", got["metadata"].(map[string]any)["requestId"])
+}
+
+func TestSample(t *testing.T) {
+	rec := &recorder{}
+
+	all := Sample(rec, 1)
+	none := Sample(rec, 0)
+	for i := 0; i < 100; i++ {
+		all.Log(decision())
+		none.Log(decision())
+	}
+	assert.Equal(t, 100, rec.len())
+}
+
+func TestRedact(t *testing.T) {
+	rec := &recorder{}
+	logger := Redact(rec, "requestId", "headers.authorization", "missing.key")
+
+	d := decision()
+	logger.Log(d)
+
+	require.Equal(t, 1, rec.len())
+	md := rec.decisions[0].Metadata
+	assert.Equal(t, Redacted, md["requestId"])
+	assert.Equal(t, Redacted, md["headers"].(map[string]any)["authorization"])
+	assert.Equal(t, "json", md["headers"].(map[string]any)["accept"])
+
+	// The caller's metadata is left untouched
+	assert.Equal(t, "abc", d.Metadata["requestId"])
+	assert.Equal(t, "Bearer secret", d.Metadata["headers"].(map[string]any)["authorization"])
+}
+
+func TestAsync(t *testing.T) {
+	t.Run("delivers buffered decisions on close", func(t *testing.T) {
+		rec := &recorder{}
+		async := NewAsync(rec, 100)
+		for i := 0; i < 50; i++ {
+			async.Log(decision())
+		}
+		require.NoError(t, async.Close())
+		assert.Equal(t, 50, rec.len())
+		assert.Zero(t, async.Dropped())
+		assert.Zero(t, async.Rejected())
+	})
+
+	t.Run("never blocks", func(t *testing.T) {
+		release := make(chan struct{})
+		rec := &recorder{}
+		slow := LoggerFunc(func(d Decision) {
+			<-release
+			rec.Log(d)
+		})
+
+		async := NewAsync(slow, 1)
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 10; i++ {
+				async.Log(decision())
+			}
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Log blocked on a slow sink")
+		}
+
+		close(release)
+		require.NoError(t, async.Close())
+		assert.Equal(t, uint64(10), async.Dropped()+uint64(rec.len()))
+		assert.NotZero(t, async.Dropped())
+
+		// Decisions after close are rejected, not delivered nor counted as dropped
+		dropped := async.Dropped()
+		async.Log(decision())
+		assert.Equal(t, dropped, async.Dropped())
+		assert.Equal(t, uint64(1), async.Rejected())
+		assert.Equal(t, uint64(10), async.Dropped()+uint64(rec.len()))
+	})
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// JSONLines writes each decision as one JSON object per line
+type JSONLines struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONLines creates a new JSON-lines sink writing to w
+func NewJSONLines(w io.Writer) *JSONLines {
+	return &JSONLines{enc: json.NewEncoder(w)}
+}
+
+func (j *JSONLines) Log(d Decision) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.enc.Encode(d); err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// Err returns the first write error, if any
+func (j *JSONLines) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.err
+}
+
+// Slog writes decisions to a structured logger
+type Slog struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewSlog creates a new sink logging decisions at info level
+func NewSlog(logger *slog.Logger) *Slog {
+	return &Slog{logger: logger, level: slog.LevelInfo}
+}
+
+// WithLevel returns a copy of the sink logging at the given level
+func (s *Slog) WithLevel(level slog.Level) *Slog {
+	return &Slog{logger: s.logger, level: level}
+}
+
+func (s *Slog) Log(d Decision) {
+	attrs := []slog.Attr{
+		slog.Time("time", d.Time),
+		slog.Any("subjects", d.Subjects),
+		slog.String("action", d.Action),
+		slog.String("object", d.Object),
+		slog.Bool("granted", d.Granted),
+		slog.Any("policies", d.Policies),
+		slog.Duration("latency", d.Latency),
+	}
+	if d.Tenant != "" {
+		attrs = append(attrs, slog.String("tenant", d.Tenant))
+	}
+	if d.Error != "" {
+		attrs = append(attrs, slog.String("error", d.Error))
+	}
+	if len(d.Metadata) > 0 {
+		md := make([]any, 0, len(d.Metadata))
+		for k, v := range d.Metadata {
+			md = append(md, slog.Any(k, v))
+		}
+		attrs = append(attrs, slog.Group("metadata", md...))
+	}
+
+	s.logger.LogAttrs(context.Background(), s.level, "authorization decision", attrs...)
+}
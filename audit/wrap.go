@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces the value of redacted metadata attributes
+const Redacted = "[REDACTED]"
+
+// Sample forwards a random fraction of the decisions, rate is between 0 and 1
+func Sample(next Logger, rate float64) Logger {
+	return LoggerFunc(func(d Decision) {
+		if rate >= 1 || rand.Float64() < rate {
+			next.Log(d)
+		}
+	})
+}
+
+// Redact replaces the given metadata attributes before forwarding decisions
+// Nested attributes use dot notation: "headers.authorization"
+// The caller's metadata map is never modified
+func Redact(next Logger, keys ...string) Logger {
+	return LoggerFunc(func(d Decision) {
+		if len(d.Metadata) > 0 {
+			d.Metadata = redact(d.Metadata, keys)
+		}
+		next.Log(d)
+	})
+}
+
+func redact(md map[string]any, keys []string) map[string]any {
+	out := make(map[string]any, len(md))
+	for k, v := range md {
+		out[k] = v
+	}
+
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		redactPath(out, parts)
+	}
+	return out
+}
+
+// redactPath redacts a path in m, copying the nested maps along the way
+func redactPath(m map[string]any, parts []string) {
+	v, ok := m[parts[0]]
+	if !ok {
+		return
+	}
+	if len(parts) == 1 {
+		m[parts[0]] = Redacted
+		return
+	}
+
+	nested, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	cp := make(map[string]any, len(nested))
+	for k, v := range nested {
+		cp[k] = v
+	}
+	redactPath(cp, parts[1:])
+	m[parts[0]] = cp
+}
+
+// Async delivers decisions to a logger on a background goroutine
+// Log never blocks, decisions are dropped when the buffer is full and
+// rejected once the logger is closed
+type Async struct {
+	next     Logger
+	ch       chan Decision
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.RWMutex
+	closed   bool
+	dropped  atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// NewAsync creates a new asynchronous logger buffering up to size decisions
+func NewAsync(next Logger, size int) *Async {
+	a := &Async{
+		next: next,
+		ch:   make(chan Decision, size),
+		done: make(chan struct{}),
+	}
+
+	go a.run()
+	return a
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+	for d := range a.ch {
+		a.next.Log(d)
+	}
+}
+
+func (a *Async) Log(d Decision) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		a.rejected.Add(1)
+		return
+	}
+
+	select {
+	case a.ch <- d:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of decisions dropped because the buffer was full
+func (a *Async) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Rejected returns the number of decisions logged after Close, none are delivered
+func (a *Async) Rejected() uint64 {
+	return a.rejected.Load()
+}
+
+// Close stops accepting decisions and waits until the buffered ones are delivered
+func (a *Async) Close() error {
+	a.once.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		close(a.ch)
+		a.mu.Unlock()
+	})
+
+	<-a.done
+	return nil
+}
+
+// Multi forwards every decision to all the loggers, in order
+func Multi(loggers ...Logger) Logger {
+	return LoggerFunc(func(d Decision) {
+		for _, l := range loggers {
+			l.Log(d)
+		}
+	})
+}
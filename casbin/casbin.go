@@ -0,0 +1,163 @@
+package casbin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Result holds the imported policies and roles
+type Result struct {
+	Policies []policy.Policy
+	Roles    *acl.Roles // Pass as acl.Options.Roles
+
+	// Report lists the model constructs and policy lines that could not be
+	// imported faithfully, an empty report means a complete import
+	Report []string
+}
+
+// Import reads Casbin policy lines, "p, sub, obj, act" rules and "g, user, role"
+// groupings, interpreted with the given model text, DefaultModel if empty
+//
+// keyMatch and keyMatch2 objects are converted to paths: "*" becomes "**" and
+// keyMatch2 ":id" segments become "*", so they match any single segment
+func Import(r io.Reader, modelText string) (*Result, error) {
+	if modelText == "" {
+		modelText = DefaultModel
+	}
+	m, err := parseModel(modelText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	res := &Result{Policies: []policy.Policy{}, Roles: acl.NewRoles(), Report: m.reports}
+	report := func(line int, format string, args ...any) {
+		res.Report = append(res.Report, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy file: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		switch ptype, values := record[0], record[1:]; {
+		case ptype == "p":
+			p, err := m.policy(values)
+			if err != nil {
+				report(line, "%v, skipped", err)
+				continue
+			}
+			res.Policies = append(res.Policies, p)
+		case ptype == "g":
+			if !m.roles {
+				report(line, "grouping ignored, the matcher does not use g")
+				continue
+			}
+			if len(values) != 2 {
+				report(line, "grouping with domains is not supported, skipped")
+				continue
+			}
+			res.Roles.Add(values[0], values[1])
+		default:
+			report(line, "policy type %q is not supported, skipped", ptype)
+		}
+	}
+	return res, nil
+}
+
+// Load reads a Casbin model file and policy file, modelPath may be empty for DefaultModel
+func Load(modelPath, policyPath string) (*Result, error) {
+	var modelText string
+	if modelPath != "" {
+		data, err := os.ReadFile(modelPath)
+		if err != nil {
+			return nil, err
+		}
+		modelText = string(data)
+	}
+
+	f, err := os.Open(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Import(f, modelText)
+}
+
+func (m *model) policy(values []string) (policy.Policy, error) {
+	field := func(name string) string {
+		if i := m.fields[name]; i >= 0 && i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+
+	if eft := field("eft"); eft != "" && eft != "allow" {
+		return policy.Policy{}, fmt.Errorf("effect %q is not supported", eft)
+	}
+
+	sub, obj, act := field("sub"), field("obj"), field("act")
+	for _, v := range []string{sub, act} {
+		if strings.ContainsAny(v, ":*{}") {
+			return policy.Policy{}, fmt.Errorf("value %q cannot be represented", v)
+		}
+	}
+
+	object, err := m.objectPath(obj)
+	if err != nil {
+		return policy.Policy{}, err
+	}
+
+	p := policy.Policy{Subject: sub, Action: act, Object: object}
+	if err := p.Validate(); err != nil {
+		return policy.Policy{}, err
+	}
+	return p, nil
+}
+
+// objectPath converts a Casbin object to a policy object
+func (m *model) objectPath(obj string) (string, error) {
+	if strings.ContainsAny(obj, "{}") {
+		return "", fmt.Errorf("object %q cannot be represented", obj)
+	}
+
+	switch m.object {
+	case matchExact:
+		if strings.ContainsAny(obj, ":*") {
+			return "", fmt.Errorf("object %q cannot be represented", obj)
+		}
+		return obj, nil
+	}
+
+	segments := strings.Split(obj, "/")
+	for i, seg := range segments {
+		switch {
+		case seg == "*":
+			segments[i] = policy.Deep
+		case m.object == matchKey2 && strings.HasPrefix(seg, ":") && len(seg) > 1:
+			segments[i] = policy.Wildcard
+		case strings.ContainsAny(seg, ":*"):
+			return "", fmt.Errorf("object pattern %q cannot be represented", obj)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,165 @@
+package casbin
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	res, err := Load("testdata/rbac_model.conf", "testdata/rbac_policy.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, []policy.Policy{
+		{Subject: "reader", Action: "read", Object: "/articles/*"},
+		{Subject: "editor", Action: "update", Object: "/articles/*"},
+		{Subject: "admin", Action: "manage", Object: "/admin/**"},
+	}, res.Policies)
+	assert.Equal(t, map[string][]string{"alice": {"editor"}, "editor": {"reader"}}, res.Roles.Map())
+	assert.Equal(t, []string{
+		`matcher term "regexMatch(r.act,p.act)" is not supported`,
+		`line 5: object pattern "/articles/draft*" cannot be represented, skipped`,
+		"line 9: grouping with domains is not supported, skipped",
+	}, res.Report)
+}
+
+func TestImport_Model(t *testing.T) {
+	res, err := Import(strings.NewReader("p, alice, data1, read, deny\np, alice, data2, read, allow\n"), `
+[request_definition]
+r = sub, obj, act
+[policy_definition]
+p = sub, obj, act, eft
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []policy.Policy{{Subject: "alice", Action: "read", Object: "data2"}}, res.Policies)
+	assert.Equal(t, []string{
+		`policy effect "some(where (p.eft == allow)) && !some(where (p.eft == deny))": deny policies are not supported`,
+		`line 1: effect "deny" is not supported, skipped`,
+	}, res.Report)
+
+	_, err = Import(strings.NewReader(""), "[matchers]\nm = true\n")
+	assert.ErrorContains(t, err, "model has no policy definition p")
+}
+
+func TestImport_Check(t *testing.T) {
+	res, err := Load("testdata/rbac_model.conf", "testdata/rbac_policy.csv")
+	require.NoError(t, err)
+
+	ac, err := acl.New(res.Policies, acl.Options{Roles: res.Roles}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		subject, action, object string
+		granted                 bool
+	}{
+		{"alice", "read", "/articles/7", true},
+		{"alice", "update", "/articles/7", true},
+		{"alice", "manage", "/admin/users", false},
+		{"editor", "read", "/articles/7", true},
+		{"reader", "update", "/articles/7", false},
+		{"bob", "manage", "/admin/users", false},
+	} {
+		perm, err := ac.Check([]string{tc.subject}, tc.action, tc.object)
+		require.NoError(t, err)
+		assert.Equal(t, tc.granted, perm.Granted(), "%s %s %s", tc.subject, tc.action, tc.object)
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	res, err := Load("testdata/rbac_model.conf", "testdata/rbac_policy.csv")
+	require.NoError(t, err)
+
+	text, model, report := Export(res.Policies, res.Roles)
+	assert.Empty(t, report)
+	assert.Equal(t, KeyMatch2Model, model)
+	assert.Equal(t, `p, reader, /articles/:seg2, read
+p, editor, /articles/:seg2, update
+p, admin, /admin/*, manage
+g, alice, editor
+g, editor, reader
+`, text)
+
+	again, err := Import(strings.NewReader(text), model)
+	require.NoError(t, err)
+	assert.Empty(t, again.Report)
+	assert.Equal(t, res.Policies, again.Policies)
+	assert.Equal(t, res.Roles.Map(), again.Roles.Map())
+}
+
+func TestExport_Issues(t *testing.T) {
+	text, model, report := Export([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Fields: []string{"title"}},
+		{Subject: "user", Action: "read", Object: "folder/1", Cascade: true},
+		{Subject: "user", Action: "read:own", Object: "article"},
+		{Subject: "*", Action: "read", Object: "article"},
+		{Subject: "user", Action: "read", Object: "reports/{year}"},
+		{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+			{Field: "status", Op: policy.OpEq, Value: "published"},
+		}},
+	}, nil)
+
+	assert.Equal(t, KeyMatch2Model, model)
+	assert.Equal(t, "p, user, article, read\np, user, folder/1, read\np, user, folder/1/*, read\n", text)
+	assert.Equal(t, []string{
+		"policy 0 (user:NULL:read:ALL:article:ANY): fields dropped",
+		"policy 2 (user:NULL:read:own:article:ANY): scopes are not supported, skipped",
+		"policy 3 (*:NULL:read:ALL:article:ANY): wildcard subjects and actions are not supported, skipped",
+		`policy 4 (user:NULL:read:ALL:reports/{year}:ANY): object "reports/{year}" is not supported, skipped`,
+		"policy 5 (user:NULL:read:ALL:article:ANY): conditions are not supported, skipped",
+	}, report)
+}
+
+func TestRoles_Expand(t *testing.T) {
+	roles := acl.NewRoles()
+	roles.Add("alice", "editor", "auditor")
+	roles.Add("editor", "reader")
+	roles.Add("reader", "editor") // Cycles are ignored
+
+	assert.Equal(t, []string{"alice", "editor", "auditor", "reader"}, roles.Expand([]string{"alice", "alice"}))
+
+	roles.Remove("alice", "editor")
+	assert.Equal(t, []string{"alice", "auditor"}, roles.Expand([]string{"alice"}))
+	roles.Remove("alice", "auditor")
+	assert.NotContains(t, roles.Map(), "alice")
+}
+
+func TestRoles_Concurrent(t *testing.T) {
+	roles := acl.NewRoles()
+	roles.Add("alice", "editor")
+	ac, err := acl.New([]policy.Policy{{Subject: "reader", Action: "read", Object: "article"}}, acl.Options{Roles: roles}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	// Run with -race: roles change while checks expand them
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := ac.Check([]string{"alice"}, "read", "article")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		roles.Add("editor", "reader")
+		roles.Remove("editor", "reader")
+	}
+	wg.Wait()
+
+	roles.Add("editor", "reader")
+	perm, err := ac.Check([]string{"alice"}, "read", "article")
+	require.NoError(t, err)
+	assert.True(t, perm.Granted())
+}
@@ -0,0 +1,123 @@
+package casbin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Export writes policies as Casbin "p" lines and roles as "g" lines, the inverse of Import
+// It returns the policy lines, the model to load them with and a report of the
+// policies skipped or exported partially
+//
+// Policies with conditions, relations, tenants, scopes or wildcard subjects and
+// actions are skipped since Casbin would grant more; fields, filters and
+// obligations are dropped
+func Export(policies []policy.Policy, roles *acl.Roles) (string, string, []string) {
+	var lines, report []string
+	patterns := false
+
+	for i, p := range policies {
+		objects, err := exportPolicy(p)
+		if err != nil {
+			report = append(report, fmt.Sprintf("policy %d (%s): %v, skipped", i, p.Key(), err))
+			continue
+		}
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"fields", len(p.Fields) > 0},
+			{"filters", len(p.Filters) > 0},
+			{"obligations", len(p.Obligations) > 0},
+		} {
+			if f.set {
+				report = append(report, fmt.Sprintf("policy %d (%s): %s dropped", i, p.Key(), f.name))
+			}
+		}
+
+		for _, obj := range objects {
+			patterns = patterns || obj != p.Object
+			lines = append(lines, line("p", p.Subject, obj, p.Action))
+		}
+	}
+
+	inherit := roles.Map()
+	subjects := make([]string, 0, len(inherit))
+	for s := range inherit {
+		subjects = append(subjects, s)
+	}
+	sort.Strings(subjects)
+	for _, s := range subjects {
+		for _, role := range inherit[s] {
+			lines = append(lines, line("g", s, role))
+		}
+	}
+
+	model := DefaultModel
+	if patterns {
+		model = KeyMatch2Model
+	}
+
+	text := strings.Join(lines, "\n")
+	if text != "" {
+		text += "\n"
+	}
+	return text, model, report
+}
+
+// exportPolicy returns the Casbin objects of a policy
+func exportPolicy(p policy.Policy) ([]string, error) {
+	switch {
+	case p.Tenant != policy.GlobalTenant:
+		return nil, fmt.Errorf("tenants are not supported")
+	case p.Relation != "":
+		return nil, fmt.Errorf("relations are not supported")
+	case len(p.Conditions) > 0:
+		return nil, fmt.Errorf("conditions are not supported")
+	case len(p.TimeWindows) > 0 || len(p.Locations) > 0:
+		return nil, fmt.Errorf("time windows and locations are not supported")
+	case strings.Contains(p.Subject, ":") || strings.Contains(p.Action, ":") || strings.Contains(p.Object, ":"):
+		return nil, fmt.Errorf("scopes are not supported")
+	case policy.IsWildcard(p.Subject) || policy.IsWildcard(p.Action):
+		return nil, fmt.Errorf("wildcard subjects and actions are not supported")
+	}
+
+	segments := strings.Split(p.Object, "/")
+	for i, seg := range segments {
+		switch {
+		case seg == policy.Deep:
+			if i != len(segments)-1 {
+				return nil, fmt.Errorf("'**' is only supported as the last segment")
+			}
+			segments[i] = "*"
+		case seg == policy.Wildcard:
+			segments[i] = fmt.Sprintf(":seg%d", i)
+		case policy.IsWildcard(seg):
+			return nil, fmt.Errorf("object %q is not supported", p.Object)
+		}
+	}
+
+	obj := strings.Join(segments, "/")
+	if !p.Cascade {
+		return []string{obj}, nil
+	}
+	if strings.HasSuffix(obj, "/*") {
+		return []string{obj}, nil
+	}
+	return []string{obj, obj + "/*"}, nil
+}
+
+// line writes a Casbin CSV line, quoting values when needed
+func line(ptype string, values ...string) string {
+	fields := append([]string{ptype}, values...)
+	for i, v := range fields {
+		if strings.ContainsAny(v, ",\"\n") || strings.TrimSpace(v) != v {
+			fields[i] = `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+		}
+	}
+	return strings.Join(fields, ", ")
+}
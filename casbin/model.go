@@ -0,0 +1,171 @@
+package casbin
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultModel is the Casbin RBAC model Import assumes when none is given
+const DefaultModel = `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+
+// KeyMatch2Model is DefaultModel matching objects with keyMatch2, used by Export
+// when objects are paths with wildcards
+const KeyMatch2Model = `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && r.act == p.act
+`
+
+// objectMatch is the way a Casbin model matches objects
+type objectMatch int
+
+const (
+	matchExact       objectMatch = iota // r.obj == p.obj
+	matchKey                            // keyMatch(r.obj, p.obj)
+	matchKey2                           // keyMatch2(r.obj, p.obj)
+	matchUnsupported                    // Anything else
+)
+
+// model is the part of a Casbin model Import understands
+type model struct {
+	fields  map[string]int // Policy field positions: sub, obj, act, eft
+	roles   bool           // The matcher follows g role inheritance
+	object  objectMatch
+	reports []string
+}
+
+var (
+	section  = regexp.MustCompile(`^\[(\w+)\]$`)
+	matchers = map[string]func(m *model){
+		"g(r.sub,p.sub)":         func(m *model) { m.roles = true },
+		"r.sub==p.sub":           func(*model) {},
+		"r.obj==p.obj":           func(m *model) { m.object = matchExact },
+		"keyMatch(r.obj,p.obj)":  func(m *model) { m.object = matchKey },
+		"keyMatch2(r.obj,p.obj)": func(m *model) { m.object = matchKey2 },
+		"r.act==p.act":           func(*model) {},
+	}
+)
+
+// parseModel reads the sections of a Casbin model and reports what it cannot import
+func parseModel(text string) (*model, error) {
+	m := &model{fields: map[string]int{"sub": 0, "obj": 1, "act": 2, "eft": -1}}
+	values := map[string]string{}
+
+	current := ""
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if s := section.FindStringSubmatch(line); s != nil {
+			current = s[1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid model line %q", line)
+		}
+		values[current+"."+strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if r := values["request_definition.r"]; compact(r) != "sub,obj,act" {
+		m.reports = append(m.reports, fmt.Sprintf("request definition %q: only sub, obj, act is supported", r))
+	}
+
+	p, ok := values["policy_definition.p"]
+	if !ok {
+		return nil, fmt.Errorf("model has no policy definition p")
+	}
+	for name := range m.fields {
+		m.fields[name] = -1
+	}
+	for i, f := range strings.Split(compact(p), ",") {
+		if _, known := m.fields[f]; !known {
+			m.reports = append(m.reports, fmt.Sprintf("policy field %q is ignored", f))
+			continue
+		}
+		m.fields[f] = i
+	}
+	for _, f := range []string{"sub", "obj", "act"} {
+		if m.fields[f] < 0 {
+			return nil, fmt.Errorf("policy definition %q has no %s field", p, f)
+		}
+	}
+
+	var extraRoles []string
+	for key := range values {
+		if strings.HasPrefix(key, "role_definition.") && key != "role_definition.g" {
+			extraRoles = append(extraRoles, key)
+		}
+	}
+	sort.Strings(extraRoles)
+	for _, key := range extraRoles {
+		m.reports = append(m.reports, fmt.Sprintf("role definition %s = %s is not supported", strings.TrimPrefix(key, "role_definition."), values[key]))
+	}
+	if g, ok := values["role_definition.g"]; ok && compact(g) != "_,_" {
+		m.reports = append(m.reports, fmt.Sprintf("role definition g = %s: only g = _, _ is supported, domains are not", g))
+	}
+
+	switch e := compact(values["policy_effect.e"]); e {
+	case "some(where(p.eft==allow))", "":
+	case "some(where(p.eft==allow))&&!some(where(p.eft==deny))", "!some(where(p.eft==deny))":
+		m.reports = append(m.reports, fmt.Sprintf("policy effect %q: deny policies are not supported", values["policy_effect.e"]))
+	default:
+		m.reports = append(m.reports, fmt.Sprintf("policy effect %q is not supported", values["policy_effect.e"]))
+	}
+
+	m.object = matchUnsupported
+	matcher := values["matchers.m"]
+	if strings.Contains(matcher, "||") {
+		m.reports = append(m.reports, fmt.Sprintf("matcher %q: || is not supported", matcher))
+	}
+	for _, term := range strings.Split(compact(matcher), "&&") {
+		apply, ok := matchers[term]
+		if !ok {
+			m.reports = append(m.reports, fmt.Sprintf("matcher term %q is not supported", term))
+			continue
+		}
+		apply(m)
+	}
+	if m.object == matchUnsupported {
+		m.reports = append(m.reports, "matcher does not compare r.obj and p.obj in a supported way, objects are matched exactly")
+		m.object = matchExact
+	}
+	return m, nil
+}
+
+// compact removes the whitespace of a model value
+func compact(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
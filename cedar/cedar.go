@@ -0,0 +1,475 @@
+package cedar
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Issue reports a Cedar construct that cannot be represented as a policy.Policy
+type Issue struct {
+	Line    int    // Line of the Cedar policy, 0 for Export
+	Policy  int    // Index of the exported policy, for Export
+	Message string // What could not be represented
+	Skipped bool   // The whole policy was left out
+	Loosens bool   // Leaving it out grants more than the Cedar policies, e.g. a skipped forbid
+}
+
+func (i Issue) String() string {
+	at := fmt.Sprintf("line %d", i.Line)
+	if i.Line == 0 {
+		at = fmt.Sprintf("policy %d", i.Policy)
+	}
+	if i.Skipped {
+		return fmt.Sprintf("%s: %s, policy skipped", at, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", at, i.Message)
+}
+
+// Result holds the translated policies and the constructs left out
+type Result struct {
+	Policies []policy.Policy
+	Issues   []Issue
+}
+
+// ParseOptions configures Parse
+type ParseOptions struct {
+	// SkipForbid imports the permit policies of sources containing forbid
+	// policies, which abacl cannot represent. Every forbid is reported as an
+	// issue that Loosens access; without it such sources fail to parse
+	SkipForbid bool
+}
+
+// Parse translates Cedar policies into policy.Policy values
+//
+// The supported subset maps:
+//   - permit policies; forbid policies fail the parse, abacl has no deny rules,
+//     see ParseOptions.SkipForbid
+//   - principal, principal == T::"id" and principal in T::"id" to the Subject "*" or "id";
+//     an id used with two entity types fails the parse, both would be the same subject
+//   - action, action == Action::"id" and action in [...] to one policy per action
+//   - resource, resource is T, resource == T::"id" and resource in T::"id" to the
+//     Object "*", "T", "T/id" and "T/id" with Cascade
+//   - when and unless conditions made of && and comparisons of resource attributes
+//     with literals, principal.* or context.* attributes, to Conditions on the
+//     record fields; attribute values are referenced as "$principal.x" and "$context.x"
+//
+// Syntax errors fail the parse, other constructs are reported as issues
+// Policies whose conditions cannot be represented are skipped rather than loosened
+// Conditions only filter records, Check grants without them, so every
+// conditioned permit is reported as an issue that Loosens access
+// Checks only use the most specific policies matching a request while Cedar
+// grants when any permit does, so permits hidden by a more specific
+// conditioned permit are reported too
+func Parse(src string) (*Result, error) {
+	return ParseWithOptions(src, ParseOptions{})
+}
+
+// ParseWithOptions is like Parse with options
+func ParseWithOptions(src string, opts ParseOptions) (*Result, error) {
+	stmts, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cedar: %w", err)
+	}
+
+	var forbids []string
+	for _, st := range stmts {
+		if st.effect == "forbid" {
+			forbids = append(forbids, fmt.Sprint(st.line))
+		}
+	}
+	if len(forbids) > 0 && !opts.SkipForbid {
+		return nil, fmt.Errorf("forbid policies at line %s cannot be represented, skipping them would grant more access", strings.Join(forbids, ", "))
+	}
+
+	if err := principalTypes(stmts); err != nil {
+		return nil, fmt.Errorf("invalid cedar: %w", err)
+	}
+
+	res := &Result{Policies: []policy.Policy{}}
+	var lines []int
+	for _, st := range stmts {
+		policies, issues := translate(st)
+		res.Policies = append(res.Policies, policies...)
+		res.Issues = append(res.Issues, issues...)
+		for range policies {
+			lines = append(lines, st.line)
+		}
+	}
+	res.Issues = append(res.Issues, shadowed(res.Policies, lines)...)
+	return res, nil
+}
+
+// principalTypes rejects principal ids named with several entity types
+// Subjects have no type, User::"admin" and Role::"admin" would both be "admin"
+func principalTypes(stmts []statement) error {
+	types := map[string]entityRef{}
+	for _, st := range stmts {
+		for _, e := range st.principal.entities {
+			if prev, ok := types[e.id]; ok && prev.typ != e.typ {
+				return fmt.Errorf("line %d: principal %s and %s would be the same subject", st.line, prev, e)
+			}
+			types[e.id] = e
+		}
+	}
+	return nil
+}
+
+// shadowed reports the permits a more specific conditioned permit hides
+// policy.MostSpecific only keeps the conditioned permit where both apply, so
+// the access of the other one is not combined with it as in Cedar
+func shadowed(policies []policy.Policy, lines []int) []Issue {
+	var issues []Issue
+	seen := map[[2]int]bool{}
+	for i, p := range policies {
+		for j, q := range policies {
+			if len(q.Conditions) == 0 || !p.Specificity().Less(q.Specificity()) || !overlaps(p, q) {
+				continue
+			}
+			pair := [2]int{lines[i], lines[j]}
+			if seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			issues = append(issues, Issue{
+				Line:    lines[i],
+				Message: fmt.Sprintf("permit is shadowed by the more specific permit at line %d where both apply, only its conditions are used", lines[j]),
+			})
+		}
+	}
+	return issues
+}
+
+// overlaps reports whether some request matches both translated policies
+func overlaps(p, q policy.Policy) bool {
+	return overlap(p.Subject, q.Subject, false, false) &&
+		overlap(p.Action, q.Action, false, false) &&
+		overlap(p.Object, q.Object, p.Cascade, q.Cascade)
+}
+
+func overlap(a, b string, cascadeA, cascadeB bool) bool {
+	return a == policy.Wildcard || b == policy.Wildcard || a == b ||
+		cascadeA && strings.HasPrefix(b, a+"/") || cascadeB && strings.HasPrefix(a, b+"/")
+}
+
+// Load reads and translates a Cedar policy file
+func Load(path string) (*Result, error) {
+	return LoadWithOptions(path, ParseOptions{})
+}
+
+// LoadWithOptions is like Load with options
+func LoadWithOptions(path string, opts ParseOptions) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithOptions(string(data), opts)
+}
+
+// translate converts a statement, returning no policies when it must be skipped
+func translate(st statement) ([]policy.Policy, []Issue) {
+	var issues []Issue
+	skip := func(format string, args ...any) ([]policy.Policy, []Issue) {
+		return nil, append(issues, Issue{Line: st.line, Message: fmt.Sprintf(format, args...), Skipped: true})
+	}
+
+	if len(st.annotations) > 0 {
+		issues = append(issues, Issue{Line: st.line, Message: "annotations @" + strings.Join(st.annotations, ", @") + " are not kept"})
+	}
+	if st.effect == "forbid" {
+		return nil, append(issues, Issue{Line: st.line, Message: "forbid cannot be represented, access is loosened", Skipped: true, Loosens: true})
+	}
+
+	var tmpl policy.Policy
+
+	subject, err := principal(st.principal)
+	if err != nil {
+		return skip("%v", err)
+	}
+	tmpl.Subject = subject
+
+	object, cascade, err := resource(st.resource)
+	if err != nil {
+		return skip("%v", err)
+	}
+	tmpl.Object, tmpl.Cascade = object, cascade
+
+	for _, cl := range st.conditions {
+		conds, err := conditions(cl)
+		if err != nil {
+			return skip("%v", err)
+		}
+		tmpl.Conditions = append(tmpl.Conditions, conds...)
+	}
+
+	actions, err := actions(st.action)
+	if err != nil {
+		return skip("%v", err)
+	}
+
+	policies := make([]policy.Policy, 0, len(actions))
+	for _, a := range actions {
+		p := tmpl
+		p.Action = a
+		if err := p.Validate(); err != nil {
+			return skip("%v", err)
+		}
+		policies = append(policies, p)
+	}
+	if len(tmpl.Conditions) > 0 {
+		issues = append(issues, Issue{Line: st.line, Message: "conditions only filter records with Rows, Check grants without them", Loosens: true})
+	}
+	return policies, issues
+}
+
+func principal(c constraint) (string, error) {
+	switch c.op {
+	case "":
+		return policy.Wildcard, nil
+	case "==", "in":
+		if c.list || len(c.entities) != 1 {
+			return "", fmt.Errorf("principal must name a single entity")
+		}
+		return c.entities[0].id, plainID(c.entities[0], "")
+	}
+	return "", fmt.Errorf("principal %s cannot be represented", c.op)
+}
+
+func actions(c constraint) ([]string, error) {
+	switch c.op {
+	case "":
+		return []string{policy.Wildcard}, nil
+	case "==", "in":
+		ids := make([]string, 0, len(c.entities))
+		for _, e := range c.entities {
+			if err := plainID(e, ""); err != nil {
+				return nil, err
+			}
+			ids = append(ids, e.id)
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("action list cannot be empty")
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("action %s cannot be represented", c.op)
+}
+
+func resource(c constraint) (string, bool, error) {
+	typ := c.typ
+	if len(c.entities) == 1 {
+		typ = c.entities[0].typ
+	}
+	if strings.Contains(typ, "::") {
+		return "", false, fmt.Errorf("namespaced resource type %s cannot be represented", typ)
+	}
+
+	switch {
+	case c.op == "":
+		return policy.Wildcard, false, nil
+	case c.op == "is" && len(c.entities) == 0:
+		return c.typ, false, nil
+	case c.list || len(c.entities) != 1:
+		return "", false, fmt.Errorf("resource must name a single entity")
+	}
+
+	e := c.entities[0]
+	if err := plainID(e, ":/"); err != nil {
+		return "", false, err
+	}
+	if c.op == "is" && c.typ != e.typ {
+		return "", false, fmt.Errorf("resource is %s in %s cannot be represented", c.typ, e)
+	}
+	return e.typ + "/" + e.id, c.op != "==", nil
+}
+
+// plainID rejects entity ids that would read as wildcards, parameters or the given separators
+func plainID(e entityRef, separators string) error {
+	if e.id == "" || strings.ContainsAny(e.id, policy.Wildcard+"{}"+separators) {
+		return fmt.Errorf("entity %s cannot be represented", e)
+	}
+	return nil
+}
+
+// Comparison operators and their negation
+var (
+	operators = map[string]policy.Operator{
+		"==": policy.OpEq, "!=": policy.OpNe,
+		"<": policy.OpLt, "<=": policy.OpLte, ">": policy.OpGt, ">=": policy.OpGte,
+	}
+	negations = map[policy.Operator]policy.Operator{
+		policy.OpEq: policy.OpNe, policy.OpNe: policy.OpEq,
+		policy.OpLt: policy.OpGte, policy.OpGte: policy.OpLt,
+		policy.OpGt: policy.OpLte, policy.OpLte: policy.OpGt,
+	}
+	flipped = map[policy.Operator]policy.Operator{
+		policy.OpEq: policy.OpEq, policy.OpNe: policy.OpNe,
+		policy.OpLt: policy.OpGt, policy.OpGt: policy.OpLt,
+		policy.OpLte: policy.OpGte, policy.OpGte: policy.OpLte,
+	}
+)
+
+// conditions converts a when or unless clause
+func conditions(cl clause) ([]policy.Condition, error) {
+	var conjuncts []expr
+	flatten(cl.expr, &conjuncts)
+
+	if cl.unless {
+		// unless { a } is when { !a }, only a single comparison stays a conjunction
+		if len(conjuncts) != 1 {
+			return nil, fmt.Errorf("unless with && cannot be represented")
+		}
+		c, err := condition(conjuncts[0])
+		if err != nil {
+			return nil, err
+		}
+		neg, ok := negations[c.Op]
+		if !ok {
+			return nil, fmt.Errorf("unless with %s cannot be represented", c.Op)
+		}
+		c.Op = neg
+		return []policy.Condition{c}, nil
+	}
+
+	conds := make([]policy.Condition, 0, len(conjuncts))
+	for _, e := range conjuncts {
+		c, err := condition(e)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+func flatten(e expr, out *[]expr) {
+	if b, ok := e.(binaryExpr); ok && b.op == "&&" {
+		flatten(b.left, out)
+		flatten(b.right, out)
+		return
+	}
+	*out = append(*out, e)
+}
+
+// condition converts a comparison of a resource attribute
+func condition(e expr) (policy.Condition, error) {
+	switch e := e.(type) {
+	case binaryExpr:
+		op, ok := operators[e.op]
+		if !ok {
+			return policy.Condition{}, fmt.Errorf("operator %s cannot be represented", e.op)
+		}
+
+		left, right := e.left, e.right
+		field, ok := resourceAttr(left)
+		if !ok {
+			// Literal on the left: 5 < resource.level
+			left, right, op = right, left, flipped[op]
+			if field, ok = resourceAttr(left); !ok {
+				return policy.Condition{}, fmt.Errorf("comparisons must involve a resource attribute")
+			}
+		}
+		value, err := operand(right)
+		if err != nil {
+			return policy.Condition{}, err
+		}
+		return policy.Condition{Field: field, Op: op, Value: value}, nil
+
+	case callExpr:
+		list, ok := e.x.(listExpr)
+		if !ok || e.method != "contains" || len(e.args) != 1 {
+			return policy.Condition{}, fmt.Errorf("method %s cannot be represented", e.method)
+		}
+		field, ok := resourceAttr(e.args[0])
+		if !ok {
+			return policy.Condition{}, fmt.Errorf("contains must test a resource attribute")
+		}
+		values := make([]any, 0, len(list.elems))
+		for _, el := range list.elems {
+			lit, ok := el.(literalExpr)
+			if !ok {
+				return policy.Condition{}, fmt.Errorf("contains lists must only hold literals")
+			}
+			values = append(values, literalValue(lit))
+		}
+		return policy.Condition{Field: field, Op: policy.OpIn, Value: values}, nil
+	}
+
+	return policy.Condition{}, fmt.Errorf("%s cannot be represented", describe(e))
+}
+
+// resourceAttr returns the record field of resource.a.b
+func resourceAttr(e expr) (string, bool) {
+	path, root, ok := attrPath(e)
+	if !ok || root != "resource" || path == "" {
+		return "", false
+	}
+	return path, true
+}
+
+// attrPath splits var.a.b into "a.b" and var
+func attrPath(e expr) (string, string, bool) {
+	var attrs []string
+	for {
+		switch x := e.(type) {
+		case accessExpr:
+			attrs = append([]string{x.attr}, attrs...)
+			e = x.x
+		case variableExpr:
+			return strings.Join(attrs, "."), x.name, true
+		default:
+			return "", "", false
+		}
+	}
+}
+
+// operand converts the value side of a comparison
+func operand(e expr) (any, error) {
+	if lit, ok := e.(literalExpr); ok {
+		return literalValue(lit), nil
+	}
+
+	path, root, ok := attrPath(e)
+	if ok && path != "" && (root == "principal" || root == "context") {
+		return "$" + root + "." + path, nil
+	}
+	return nil, fmt.Errorf("%s cannot be compared", describe(e))
+}
+
+func literalValue(lit literalExpr) any {
+	// Strings starting with "$" would read as attribute references
+	if s, ok := lit.value.(string); ok && strings.HasPrefix(s, "$") {
+		return "$" + s
+	}
+	return lit.value
+}
+
+func describe(e expr) string {
+	switch e := e.(type) {
+	case binaryExpr:
+		return "operator " + e.op
+	case unaryExpr:
+		return "operator " + e.op
+	case hasExpr:
+		return "has"
+	case likeExpr:
+		return "like"
+	case ifExpr:
+		return "if-then-else"
+	case callExpr:
+		return "method " + e.method
+	case entityExpr:
+		return "entity " + e.ref.String()
+	case listExpr:
+		return "set"
+	case variableExpr:
+		return e.name
+	case accessExpr:
+		if path, root, ok := attrPath(e); ok {
+			return root + "." + path
+		}
+	}
+	return "expression"
+}
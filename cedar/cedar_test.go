@@ -0,0 +1,139 @@
+package cedar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	res, err := LoadWithOptions("testdata/documents.cedar", ParseOptions{SkipForbid: true})
+	require.NoError(t, err)
+
+	published := []policy.Condition{
+		{Field: "status", Op: policy.OpEq, Value: "published"},
+		{Field: "level", Op: policy.OpLte, Value: "$principal.clearance"},
+	}
+	assert.Equal(t, []policy.Policy{
+		{Subject: "user", Action: "read", Object: "document", Conditions: published},
+		{Subject: "user", Action: "list", Object: "document", Conditions: published},
+		{Subject: "user", Action: "update", Object: "document", Conditions: []policy.Condition{
+			{Field: "owner", Op: policy.OpEq, Value: "$principal.id"},
+			{Field: "locked", Op: policy.OpNe, Value: true},
+		}},
+		{Subject: "admin", Action: "*", Object: "folder/42", Cascade: true},
+		{Subject: "*", Action: "read", Object: "document/handbook", Conditions: []policy.Condition{
+			{Field: "visibility", Op: policy.OpIn, Value: []any{"public", "internal"}},
+		}},
+	}, res.Policies)
+
+	require.Len(t, res.Issues, 6)
+	assert.Equal(t, "line 3: annotations @id are not kept", res.Issues[0].String())
+	for i, line := range []int{3, 11, 25} {
+		assert.Equal(t, fmt.Sprintf("line %d: conditions only filter records with Rows, Check grants without them", line), res.Issues[i+1].String())
+		assert.True(t, res.Issues[i+1].Loosens)
+	}
+	assert.Equal(t, "line 32: forbid cannot be represented, access is loosened, policy skipped", res.Issues[4].String())
+	assert.True(t, res.Issues[4].Loosens)
+	assert.Equal(t, "line 38: operator || cannot be represented, policy skipped", res.Issues[5].String())
+	assert.False(t, res.Issues[5].Loosens)
+}
+
+func TestParse_Shadowed(t *testing.T) {
+	res, err := Parse(`permit (principal, action == Action::"read", resource is document);
+permit (principal == User::"alice", action == Action::"read", resource is document)
+when { resource.owner == "alice" };`)
+	require.NoError(t, err)
+	require.Len(t, res.Policies, 2)
+
+	require.Len(t, res.Issues, 2)
+	assert.Equal(t, "line 2: conditions only filter records with Rows, Check grants without them", res.Issues[0].String())
+	assert.Equal(t, "line 1: permit is shadowed by the more specific permit at line 2 where both apply, only its conditions are used", res.Issues[1].String())
+
+	// The issue describes what checks do: alice only gets her own rows while bob gets every row
+	ac, err := acl.New(res.Policies, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+	records := []map[string]any{{"id": 1, "owner": "alice"}, {"id": 2, "owner": "bob"}}
+	for subject, want := range map[string]int{"alice": 1, "bob": 2} {
+		perm, err := ac.Check([]string{subject}, "read", "document")
+		require.NoError(t, err)
+		rows, err := perm.Rows(records, nil)
+		require.NoError(t, err)
+		assert.Len(t, rows, want, subject)
+	}
+}
+
+func TestParse_PrincipalTypes(t *testing.T) {
+	_, err := Parse(`permit (principal == User::"admin", action, resource);
+permit (principal in Role::"admin", action == Action::"read", resource);`)
+	assert.ErrorContains(t, err, `line 2: principal User::"admin" and Role::"admin" would be the same subject`)
+}
+
+func TestParse_Forbid(t *testing.T) {
+	_, err := Load("testdata/documents.cedar")
+	assert.ErrorContains(t, err, "forbid policies at line 32 cannot be represented")
+
+	res, err := Parse(`permit (principal == Role::"user", action == Action::"read", resource is article);`)
+	require.NoError(t, err)
+	assert.Len(t, res.Policies, 1)
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	_, err := Parse(`permit (principal, action resource);`)
+	assert.ErrorContains(t, err, `line 1: expected ","`)
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	res, err := LoadWithOptions("testdata/documents.cedar", ParseOptions{SkipForbid: true})
+	require.NoError(t, err)
+
+	text, issues := Export(res.Policies, ExportOptions{})
+	assert.Empty(t, issues)
+
+	again, err := Parse(text)
+	require.NoError(t, err)
+	for _, issue := range again.Issues {
+		assert.Contains(t, issue.Message, "conditions only filter records")
+	}
+	assert.Equal(t, res.Policies, again.Policies)
+}
+
+func TestExport_Issues(t *testing.T) {
+	text, issues := Export([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Filters: []string{"title"}},
+		{Subject: "user", Action: "read", Object: "reports/*"},
+	}, ExportOptions{PrincipalType: "Group"})
+
+	assert.Equal(t, "permit (\n    principal in Group::\"user\",\n    action == Action::\"read\",\n    resource is article\n);\n", text)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "policy 0: filters cannot be exported", issues[0].String())
+	assert.Equal(t, `policy 1: object "reports/*" cannot be exported, policy skipped`, issues[1].String())
+}
+
+func TestParse_Check(t *testing.T) {
+	res, err := LoadWithOptions("testdata/documents.cedar", ParseOptions{SkipForbid: true})
+	require.NoError(t, err)
+
+	ac, err := acl.New(res.Policies, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	perm, err := ac.Check([]string{"user"}, "read", "document")
+	require.NoError(t, err)
+	rows, err := perm.Rows([]map[string]any{
+		{"id": 1, "status": "published", "level": 1},
+		{"id": 2, "status": "draft", "level": 1},
+		{"id": 3, "status": "published", "level": 5},
+	}, map[string]any{"principal": map[string]any{"clearance": 2}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0]["id"])
+
+	perm, err = ac.Check([]string{"admin"}, "delete", "folder/42/document/7")
+	require.NoError(t, err)
+	assert.True(t, perm.Granted())
+}
@@ -0,0 +1,219 @@
+package cedar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+var cedarIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExportOptions names the Cedar entity types of exported policies
+type ExportOptions struct {
+	PrincipalType string // Defaults to "Role"
+	ActionType    string // Defaults to "Action"
+}
+
+// Export writes policies as Cedar, the inverse of Parse for its supported subset
+// Policies outside the subset are skipped and policy features Cedar has no
+// counterpart for, such as Fields and Filters, are left out; both are reported
+// as issues
+func Export(policies []policy.Policy, opts ExportOptions) (string, []Issue) {
+	if opts.PrincipalType == "" {
+		opts.PrincipalType = "Role"
+	}
+	if opts.ActionType == "" {
+		opts.ActionType = "Action"
+	}
+
+	var sb strings.Builder
+	var issues []Issue
+	for i, p := range policies {
+		text, dropped, err := export(p, opts)
+		if err != nil {
+			issues = append(issues, Issue{Policy: i, Message: err.Error(), Skipped: true})
+			continue
+		}
+		for _, d := range dropped {
+			issues = append(issues, Issue{Policy: i, Message: d + " cannot be exported"})
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(text)
+	}
+	return sb.String(), issues
+}
+
+func export(p policy.Policy, opts ExportOptions) (string, []string, error) {
+	if p.Tenant != policy.GlobalTenant {
+		return "", nil, fmt.Errorf("tenant policies cannot be exported")
+	}
+	if p.Relation != "" {
+		return "", nil, fmt.Errorf("relations cannot be exported")
+	}
+
+	var dropped []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"fields", len(p.Fields) > 0},
+		{"filters", len(p.Filters) > 0},
+		{"obligations", len(p.Obligations) > 0},
+		{"time windows", len(p.TimeWindows) > 0},
+		{"locations", len(p.Locations) > 0},
+	} {
+		if f.set {
+			dropped = append(dropped, f.name)
+		}
+	}
+
+	principal, err := exportEntity("principal", "in", opts.PrincipalType, p.Subject)
+	if err != nil {
+		return "", nil, err
+	}
+	action, err := exportEntity("action", "==", opts.ActionType, p.Action)
+	if err != nil {
+		return "", nil, err
+	}
+	resource, err := exportResource(p)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "permit (\n    %s,\n    %s,\n    %s\n)", principal, action, resource)
+
+	if len(p.Conditions) > 0 {
+		conds := make([]string, 0, len(p.Conditions))
+		for _, c := range p.Conditions {
+			text, err := exportCondition(c)
+			if err != nil {
+				return "", nil, err
+			}
+			conds = append(conds, text)
+		}
+		fmt.Fprintf(&sb, "\nwhen { %s }", strings.Join(conds, " && "))
+	}
+	sb.WriteString(";\n")
+	return sb.String(), dropped, nil
+}
+
+func exportEntity(variable, op, typ, name string) (string, error) {
+	if name == policy.Wildcard {
+		return variable, nil
+	}
+	if policy.IsWildcard(name) {
+		return "", fmt.Errorf("%s %q: globs cannot be exported", variable, name)
+	}
+	return fmt.Sprintf("%s %s %s::%s", variable, op, typ, quote(name)), nil
+}
+
+func exportResource(p policy.Policy) (string, error) {
+	if p.Object == policy.Wildcard && !p.Cascade {
+		return "resource", nil
+	}
+	if policy.IsWildcard(p.Object) || strings.Contains(p.Object, ":") {
+		return "", fmt.Errorf("object %q cannot be exported", p.Object)
+	}
+
+	typ, id, ok := strings.Cut(p.Object, "/")
+	switch {
+	case !cedarIdent.MatchString(typ) || strings.Contains(id, "/"):
+		return "", fmt.Errorf("object %q cannot be exported", p.Object)
+	case !ok && p.Cascade:
+		return "", fmt.Errorf("cascading object %q cannot be exported", p.Object)
+	case !ok:
+		return "resource is " + typ, nil
+	case p.Cascade:
+		return fmt.Sprintf("resource in %s::%s", typ, quote(id)), nil
+	}
+	return fmt.Sprintf("resource == %s::%s", typ, quote(id)), nil
+}
+
+// Cedar operators of the policy operators
+var cedarOps = map[policy.Operator]string{
+	policy.OpEq: "==", policy.OpNe: "!=",
+	policy.OpLt: "<", policy.OpLte: "<=", policy.OpGt: ">", policy.OpGte: ">=",
+}
+
+func exportCondition(c policy.Condition) (string, error) {
+	field, err := exportPath("resource", c.Field)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Op == policy.OpIn {
+		values, ok := policy.Values(c.Value)
+		if !ok {
+			return "", fmt.Errorf("condition %s in: attribute lists cannot be exported", c.Field)
+		}
+		elems := make([]string, 0, len(values))
+		for _, v := range values {
+			text, err := exportLiteral(v)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, text)
+		}
+		return fmt.Sprintf("[%s].contains(%s)", strings.Join(elems, ", "), field), nil
+	}
+
+	op, ok := cedarOps[c.Op]
+	if !ok {
+		return "", fmt.Errorf("operator %s cannot be exported", c.Op)
+	}
+	value, err := exportValue(c.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", field, op, value), nil
+}
+
+// exportValue writes a literal or a "$principal.x" or "$context.x" reference
+func exportValue(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "$") || strings.HasPrefix(s, "$$") {
+		return exportLiteral(v)
+	}
+
+	root, path, _ := strings.Cut(s[1:], ".")
+	if (root != "principal" && root != "context") || path == "" {
+		return "", fmt.Errorf("attribute %s cannot be exported, only $principal.* and $context.*", s)
+	}
+	return exportPath(root, path)
+}
+
+func exportLiteral(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		// "$$" escapes a literal dollar sign
+		if strings.HasPrefix(v, "$$") {
+			v = v[1:]
+		}
+		return quote(v), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return fmt.Sprint(v), nil
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprint(int64(v)), nil
+		}
+	}
+	return "", fmt.Errorf("value %v cannot be exported", v)
+}
+
+func exportPath(root, path string) (string, error) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts {
+		if !cedarIdent.MatchString(part) {
+			return "", fmt.Errorf("attribute %s cannot be exported", path)
+		}
+	}
+	return root + "." + strings.Join(parts, "."), nil
+}
@@ -0,0 +1,159 @@
+package cedar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string // Identifier, punctuation or unquoted string
+	num  int64
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Punctuation, longest first
+var puncts = []string{"::", "==", "!=", "<=", ">=", "&&", "||", "(", ")", "{", "}", "[", "]", ",", ";", ".", "<", ">", "!", "@", "-", "+", "*"}
+
+// lex splits Cedar source into tokens, skipping whitespace and // comments
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			n, err := strconv.ParseInt(src[i:j], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %s", line, src[i:j])
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:j], num: n, line: line})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+		default:
+			p := ""
+			for _, candidate := range puncts {
+				if strings.HasPrefix(src[i:], candidate) {
+					p = candidate
+					break
+				}
+			}
+			if p == "" {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+			}
+			tokens = append(tokens, token{kind: tokPunct, text: p, line: line})
+			i += len(p)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, line: line}), nil
+}
+
+// lexString reads a quoted string, returning its value and length in src
+func lexString(src string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(src) {
+				break
+			}
+			switch src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			case '\\', '"', '\'', '*':
+				if src[i] == '*' {
+					sb.WriteByte('\\')
+				}
+				sb.WriteByte(src[i])
+			default:
+				return "", 0, fmt.Errorf("unsupported string escape \\%c", src[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// quote writes a Cedar string literal
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
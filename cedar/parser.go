@@ -0,0 +1,510 @@
+package cedar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parsed Cedar syntax, before translation
+
+type statement struct {
+	line        int
+	annotations []string
+	effect      string // permit or forbid
+	principal   constraint
+	action      constraint
+	resource    constraint
+	conditions  []clause
+}
+
+// constraint is a scope constraint: principal, principal == E, action in [E...], resource is T in E
+type constraint struct {
+	op       string // "", "==", "in" or "is"
+	typ      string // Entity type for "is"
+	entities []entityRef
+	list     bool // Entities were written as a list
+}
+
+type clause struct {
+	unless bool
+	expr   expr
+}
+
+type entityRef struct {
+	typ string // Possibly namespaced: App::User
+	id  string
+}
+
+func (e entityRef) String() string {
+	return e.typ + "::" + quote(e.id)
+}
+
+type expr interface{}
+
+type (
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+	unaryExpr struct {
+		op string
+		x  expr
+	}
+	literalExpr struct {
+		value any // string, int64 or bool
+	}
+	variableExpr struct {
+		name string // principal, action, resource or context
+	}
+	entityExpr struct {
+		ref entityRef
+	}
+	accessExpr struct {
+		x    expr
+		attr string
+	}
+	callExpr struct {
+		x      expr
+		method string
+		args   []expr
+	}
+	listExpr struct {
+		elems []expr
+	}
+	hasExpr struct {
+		x    expr
+		attr string
+	}
+	likeExpr struct {
+		x       expr
+		pattern string
+	}
+	ifExpr struct {
+		cond, then, els expr
+	}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) ([]statement, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var stmts []statement
+	for p.peek().kind != tokEOF {
+		st, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, st)
+	}
+	return stmts, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given punctuation or keyword
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokPunct || t.kind == tokIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q, found %s", text, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.peek().line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("line %d: expected identifier, found %s", t.line, t)
+	}
+	return t.text, nil
+}
+
+func (p *parser) str() (string, error) {
+	t := p.next()
+	if t.kind != tokString {
+		return "", fmt.Errorf("line %d: expected string, found %s", t.line, t)
+	}
+	return t.text, nil
+}
+
+func (p *parser) statement() (statement, error) {
+	st := statement{line: p.peek().line}
+
+	for p.accept("@") {
+		name, err := p.ident()
+		if err != nil {
+			return st, err
+		}
+		if err := p.expect("("); err != nil {
+			return st, err
+		}
+		if _, err := p.str(); err != nil {
+			return st, err
+		}
+		if err := p.expect(")"); err != nil {
+			return st, err
+		}
+		st.annotations = append(st.annotations, name)
+	}
+
+	effect, err := p.ident()
+	if err != nil {
+		return st, err
+	}
+	if effect != "permit" && effect != "forbid" {
+		return st, fmt.Errorf("line %d: expected permit or forbid, found %q", st.line, effect)
+	}
+	st.effect = effect
+
+	if err := p.expect("("); err != nil {
+		return st, err
+	}
+	for i, v := range []string{"principal", "action", "resource"} {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return st, err
+			}
+		}
+		if err := p.expect(v); err != nil {
+			return st, err
+		}
+		c, err := p.constraint()
+		if err != nil {
+			return st, err
+		}
+		switch v {
+		case "principal":
+			st.principal = c
+		case "action":
+			st.action = c
+		case "resource":
+			st.resource = c
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return st, err
+	}
+
+	for {
+		var unless bool
+		switch {
+		case p.accept("when"):
+		case p.accept("unless"):
+			unless = true
+		default:
+			return st, p.expect(";")
+		}
+
+		if err := p.expect("{"); err != nil {
+			return st, err
+		}
+		e, err := p.expr()
+		if err != nil {
+			return st, err
+		}
+		if err := p.expect("}"); err != nil {
+			return st, err
+		}
+		st.conditions = append(st.conditions, clause{unless: unless, expr: e})
+	}
+}
+
+func (p *parser) constraint() (constraint, error) {
+	var c constraint
+	switch {
+	case p.accept("=="):
+		c.op = "=="
+	case p.accept("in"):
+		c.op = "in"
+	case p.accept("is"):
+		c.op = "is"
+		typ, err := p.path()
+		if err != nil {
+			return c, err
+		}
+		c.typ = typ
+		if !p.accept("in") {
+			return c, nil
+		}
+	default:
+		return c, nil
+	}
+
+	if p.accept("[") {
+		c.list = true
+		for !p.accept("]") {
+			if len(c.entities) > 0 {
+				if err := p.expect(","); err != nil {
+					return c, err
+				}
+			}
+			e, err := p.entity()
+			if err != nil {
+				return c, err
+			}
+			c.entities = append(c.entities, e)
+		}
+		return c, nil
+	}
+
+	e, err := p.entity()
+	if err != nil {
+		return c, err
+	}
+	c.entities = append(c.entities, e)
+	return c, nil
+}
+
+// path reads a possibly namespaced type name: App::User
+func (p *parser) path() (string, error) {
+	parts := []string{}
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, name)
+
+		if p.peek().text != "::" || p.tokens[p.pos+1].kind != tokIdent {
+			return strings.Join(parts, "::"), nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) entity() (entityRef, error) {
+	typ, err := p.path()
+	if err != nil {
+		return entityRef{}, err
+	}
+	if err := p.expect("::"); err != nil {
+		return entityRef{}, err
+	}
+	id, err := p.str()
+	if err != nil {
+		return entityRef{}, err
+	}
+	return entityRef{typ: typ, id: id}, nil
+}
+
+func (p *parser) expr() (expr, error) {
+	if p.accept("if") {
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		then, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("else"); err != nil {
+			return nil, err
+		}
+		els, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return ifExpr{cond: cond, then: then, els: els}, nil
+	}
+	return p.binary(0)
+}
+
+// Binary operators by increasing precedence
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*"},
+}
+
+func (p *parser) binary(level int) (expr, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if level == 2 {
+			switch {
+			case p.accept("has"):
+				attr, err := p.attrName()
+				if err != nil {
+					return nil, err
+				}
+				left = hasExpr{x: left, attr: attr}
+				continue
+			case p.accept("like"):
+				pattern, err := p.str()
+				if err != nil {
+					return nil, err
+				}
+				left = likeExpr{x: left, pattern: pattern}
+				continue
+			}
+		}
+
+		op := ""
+		for _, candidate := range precedence[level] {
+			if p.accept(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		return unaryExpr{op: "!", x: x}, err
+	}
+	if p.peek().text == "-" && p.tokens[p.pos+1].kind == tokNumber {
+		p.next()
+		return literalExpr{value: -p.next().num}, nil
+	}
+	if p.accept("-") {
+		x, err := p.unary()
+		return unaryExpr{op: "-", x: x}, err
+	}
+	return p.member()
+}
+
+func (p *parser) member() (expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("."):
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept("(") {
+				x = accessExpr{x: x, attr: name}
+				continue
+			}
+			args, err := p.args(")")
+			if err != nil {
+				return nil, err
+			}
+			x = callExpr{x: x, method: name, args: args}
+		case p.peek().text == "[" && p.tokens[p.pos+1].kind == tokString:
+			p.next()
+			attr := p.next().text
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = accessExpr{x: x, attr: attr}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) args(end string) ([]expr, error) {
+	var args []expr
+	for !p.accept(end) {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+	}
+	return args, nil
+}
+
+func (p *parser) attrName() (string, error) {
+	if p.peek().kind == tokString {
+		return p.str()
+	}
+	return p.ident()
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return literalExpr{value: t.text}, nil
+	case tokNumber:
+		p.next()
+		return literalExpr{value: t.num}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return literalExpr{value: t.text == "true"}, nil
+		case "principal", "action", "resource", "context":
+			p.next()
+			return variableExpr{name: t.text}, nil
+		}
+		e, err := p.entity()
+		return entityExpr{ref: e}, err
+	}
+
+	switch {
+	case p.accept("("):
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case p.accept("["):
+		elems, err := p.args("]")
+		return listExpr{elems: elems}, err
+	}
+	return nil, p.errorf("unexpected %s", t)
+}
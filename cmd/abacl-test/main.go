@@ -0,0 +1,55 @@
+// Command abacl-test runs declarative policy test suites
+//
+// Usage:
+//
+//	abacl-test [-v] suite.yaml [suite.json ...]
+//
+// It exits with status 1 when a case fails and 2 when a suite cannot be loaded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alipourhabibi/abacl-go/policytest"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "print passing cases and their decision traces")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: abacl-test [-v] suite.yaml [suite.json ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		suite, err := policytest.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(2)
+		}
+
+		result, err := suite.Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(2)
+		}
+
+		fmt.Printf("== %s\n", path)
+		result.Write(os.Stdout, *verbose)
+		if result.Failed() > 0 {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
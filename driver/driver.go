@@ -1,6 +1,11 @@
 package driver
 
-import "github.com/alipourhabibi/abacl-go/policy"
+import (
+	"context"
+	"fmt"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
 
 // Driver defines the storage interface for policies
 type Driver interface {
@@ -11,6 +16,7 @@ type Driver interface {
 	Get(key string) (policy.Policy, bool)
 
 	// Find searches for policies matching a pattern (using regex on keys)
+	// Only policies of the pattern's tenant may be returned, in policy.Sort order
 	Find(patternPolicy policy.Policy) ([]policy.Policy, error)
 
 	// Delete removes a policy
@@ -22,6 +28,54 @@ type Driver interface {
 	// Clear removes all policies
 	Clear() error
 
-	// List returns all policy keys
+	// List returns all policy keys, sorted
 	List() []string
 }
+
+// TenantDriver is implemented by drivers that list and clear the policies of
+// a single tenant natively, the other drivers are scanned with List and Get
+type TenantDriver interface {
+	// ListTenant returns the policy keys of a single tenant, sorted
+	ListTenant(tenant string) []string
+
+	// ClearTenant removes all policies of a single tenant
+	ClearTenant(tenant string) error
+}
+
+// ListTenant returns the sorted policy keys of a single tenant, through
+// TenantDriver when d implements it
+func ListTenant(d Driver, tenant string) []string {
+	if t, ok := d.(TenantDriver); ok {
+		return t.ListTenant(tenant)
+	}
+
+	keys := []string{}
+	for _, key := range d.List() {
+		if p, ok := d.Get(key); ok && p.Tenant == tenant {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ClearTenant removes all policies of a single tenant, through TenantDriver
+// when d implements it
+func ClearTenant(d Driver, tenant string) error {
+	if t, ok := d.(TenantDriver); ok {
+		return t.ClearTenant(tenant)
+	}
+
+	for _, key := range ListTenant(d, tenant) {
+		if err := d.Delete(key); err != nil {
+			return fmt.Errorf("failed to remove %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ContextFinder is implemented by drivers whose lookups accept a context,
+// e.g. to cancel slow queries or to carry tracing spans
+type ContextFinder interface {
+	// FindContext is like Find but takes the context of the check
+	FindContext(ctx context.Context, patternPolicy policy.Policy) ([]policy.Policy, error)
+}
@@ -0,0 +1,31 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenant_Fallback(t *testing.T) {
+	d := &keyedDriver{policies: map[string]policy.Policy{}}
+	for _, p := range []policy.Policy{
+		{Subject: "user", Action: "read", Object: "article"},
+		{Tenant: "acme", Subject: "editor", Action: "update", Object: "article"},
+		{Tenant: "acme", Subject: "user", Action: "read", Object: "invoice"},
+		{Tenant: "globex", Subject: "user", Action: "read", Object: "article"},
+	} {
+		require.NoError(t, d.Set(p))
+	}
+
+	_, ok := any(d).(TenantDriver)
+	require.False(t, ok, "the fallback scans List and Get")
+
+	assert.Equal(t, []string{"acme@editor:NULL:update:ALL:article:ANY", "acme@user:NULL:read:ALL:invoice:ANY"}, ListTenant(d, "acme"))
+	assert.Equal(t, []string{"user:NULL:read:ALL:article:ANY"}, ListTenant(d, policy.GlobalTenant))
+	assert.Empty(t, ListTenant(d, "initech"))
+
+	require.NoError(t, ClearTenant(d, "acme"))
+	assert.Equal(t, []string{"globex@user:NULL:read:ALL:article:ANY", "user:NULL:read:ALL:article:ANY"}, d.List())
+}
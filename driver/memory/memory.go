@@ -2,26 +2,74 @@ package memory
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/alipourhabibi/abacl-go/driver"
 	"github.com/alipourhabibi/abacl-go/policy"
 )
 
 // MemoryDriver provides an in-memory implementation of the Driver interface
+// It also implements driver.Versioned by keeping a log of every mutation
 type MemoryDriver struct {
 	mu       sync.RWMutex
+	opts     Options
 	policies map[string]policy.Policy
+	history  []entry
+	base     map[string]policy.Policy // Policies as of the last dropped revision
+	dropped  int64                    // Revisions dropped from the start of the history
 }
 
-// NewMemoryDriver creates a new in-memory driver
+// entry is a recorded mutation, replaying the log rebuilds any revision
+type entry struct {
+	rev      driver.Revision
+	policy   policy.Policy            // Stored policy for set
+	restored map[string]policy.Policy // Restored or changed policies for rollback
+	removed  []string                 // Removed keys for rollback
+
+	// checkpoint holds the policies after the mutation, every Options.CheckpointEvery revisions
+	checkpoint map[string]policy.Policy
+}
+
+// DefaultCheckpointEvery is the checkpoint interval used when Options.CheckpointEvery is 0
+const DefaultCheckpointEvery = 100
+
+// Options configures the history of a MemoryDriver
+type Options struct {
+	// MaxRevisions bounds the history, older revisions are dropped and can no
+	// longer be read or rolled back to; 0 keeps every revision
+	MaxRevisions int
+
+	// CheckpointEvery stores a copy of the policies every so many revisions, so
+	// reading a past revision replays at most that many mutations
+	CheckpointEvery int
+}
+
+// NewMemoryDriver creates a new in-memory driver keeping every revision
 func NewMemoryDriver() *MemoryDriver {
+	return NewMemoryDriverWithOptions(Options{})
+}
+
+// NewMemoryDriverWithOptions creates a new in-memory driver with the given history options
+func NewMemoryDriverWithOptions(opts Options) *MemoryDriver {
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = DefaultCheckpointEvery
+	}
 	return &MemoryDriver{
+		opts:     opts,
 		policies: make(map[string]policy.Policy),
+		base:     make(map[string]policy.Policy),
 	}
 }
 
 func (m *MemoryDriver) Set(p policy.Policy) error {
+	return m.SetAs("", p)
+}
+
+func (m *MemoryDriver) SetAs(author string, p policy.Policy) error {
 	if err := p.Validate(); err != nil {
 		return fmt.Errorf("invalid policy: %w", err)
 	}
@@ -29,7 +77,10 @@ func (m *MemoryDriver) Set(p policy.Policy) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.policies[p.Key()] = p
+	m.record(entry{
+		rev:    driver.Revision{Author: author, Op: driver.OpSet, Key: p.Key()},
+		policy: p,
+	})
 	return nil
 }
 
@@ -43,33 +94,83 @@ func (m *MemoryDriver) Get(key string) (policy.Policy, bool) {
 
 // Find searches for policies using regex matching on keys
 // This is your original implementation that worked
+// The pattern must match whole keys, "user" does not find "superuser" policies
+// Policies with wildcards match through policy.Instantiate, only the most
+// specific matches are returned, see policy.MostSpecific
 func (m *MemoryDriver) Find(patternPolicy policy.Policy) ([]policy.Policy, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return find(m.policies, patternPolicy)
+}
+
+func (m *MemoryDriver) FindAt(rev int64, patternPolicy policy.Policy) ([]policy.Policy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policies, err := m.replay(rev)
+	if err != nil {
+		return nil, err
+	}
+	return find(policies, patternPolicy)
+}
+
+func find(policies map[string]policy.Policy, patternPolicy policy.Policy) ([]policy.Policy, error) {
 	// Get the pattern key (which may contain regex like \w+)
 	patternKey := patternPolicy.Key()
 
-	re, err := regexp.Compile(patternKey)
+	re, err := regexp.Compile("^(?:" + patternKey + ")$")
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
 
 	var results []policy.Policy
-	for key, p := range m.policies {
-		if re.MatchString(key) {
+	for k, p := range policies {
+		// Keys are matched unanchored, so tenants are compared explicitly
+		if p.Tenant != patternPolicy.Tenant {
+			continue
+		}
+		if p.IsPattern() {
+			// Wildcard policies are matched as if they named the pattern's bases
+			inst, ok := p.Instantiate(patternPolicy)
+			if !ok {
+				continue
+			}
+			k = inst.Key()
+		}
+		if re.MatchString(k) {
 			results = append(results, p)
 		}
 	}
 
+	results = policy.MostSpecific(results)
+	policy.Sort(results)
 	return results, nil
 }
 
+func (m *MemoryDriver) FindPage(patternPolicy policy.Policy, req driver.PageRequest) (driver.Page[policy.Policy], error) {
+	policies, err := m.Find(patternPolicy)
+	if err != nil {
+		return driver.Page[policy.Policy]{}, err
+	}
+	// The most specific policies share their specificity, so they are sorted by key
+	return driver.Paginate(policies, func(p policy.Policy) string { return p.Key() }, req)
+}
+
 func (m *MemoryDriver) Delete(key string) error {
+	return m.DeleteAs("", key)
+}
+
+// DeleteAs records no revision when key is not stored, so no-op deletes do
+// not fill the history
+func (m *MemoryDriver) DeleteAs(author string, key string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	delete(m.policies, key)
+	if _, ok := m.policies[key]; !ok {
+		return nil
+	}
+	m.record(entry{rev: driver.Revision{Author: author, Op: driver.OpDelete, Key: key}})
 	return nil
 }
 
@@ -82,11 +183,14 @@ func (m *MemoryDriver) Exists(key string) bool {
 }
 
 func (m *MemoryDriver) Clear() error {
+	return m.ClearAs("")
+}
+
+func (m *MemoryDriver) ClearAs(author string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Correctly clear the map
-	m.policies = make(map[string]policy.Policy)
+	m.record(entry{rev: driver.Revision{Author: author, Op: driver.OpClear}})
 	return nil
 }
 
@@ -98,5 +202,200 @@ func (m *MemoryDriver) List() []string {
 	for k := range m.policies {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return keys
 }
+
+func (m *MemoryDriver) ListPage(req driver.PageRequest) (driver.Page[string], error) {
+	return driver.Paginate(m.List(), keyOf, req)
+}
+
+func (m *MemoryDriver) ListTenant(tenant string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []string{}
+	for k, p := range m.policies {
+		if p.Tenant == tenant {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *MemoryDriver) ListTenantPage(tenant string, req driver.PageRequest) (driver.Page[string], error) {
+	return driver.Paginate(m.ListTenant(tenant), keyOf, req)
+}
+
+func keyOf(k string) string {
+	return k
+}
+
+func (m *MemoryDriver) ClearTenant(tenant string) error {
+	return m.ClearTenantAs("", tenant)
+}
+
+func (m *MemoryDriver) ClearTenantAs(author string, tenant string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.record(entry{rev: driver.Revision{Author: author, Op: driver.OpClearTenant, Tenant: tenant}})
+	return nil
+}
+
+func (m *MemoryDriver) Revisions() []driver.Revision {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	revs := make([]driver.Revision, len(m.history))
+	for i, e := range m.history {
+		revs[i] = e.rev
+	}
+	return revs
+}
+
+func (m *MemoryDriver) Snapshot(rev int64) ([]policy.Policy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policies, err := m.replay(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(policies))
+	for k := range policies {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	snapshot := make([]policy.Policy, 0, len(policies))
+	for _, k := range keys {
+		snapshot = append(snapshot, policies[k])
+	}
+	return snapshot, nil
+}
+
+func (m *MemoryDriver) Rollback(author string, rev int64) error {
+	return m.rollback(driver.Revision{Author: author, Op: driver.OpRollback, Target: rev}, func(policy.Policy) bool { return true })
+}
+
+func (m *MemoryDriver) RollbackTenant(author string, tenant string, rev int64) error {
+	return m.rollback(driver.Revision{Author: author, Op: driver.OpRollback, Tenant: tenant, Target: rev},
+		func(p policy.Policy) bool { return p.Tenant == tenant })
+}
+
+// rollback records the changes restoring the policies selected by owned as of
+// rev.Target, in a single revision
+func (m *MemoryDriver) rollback(rev driver.Revision, owned func(policy.Policy) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target, err := m.replay(rev.Target)
+	if err != nil {
+		return err
+	}
+
+	e := entry{rev: rev, restored: map[string]policy.Policy{}}
+	for k, p := range m.policies {
+		if _, ok := target[k]; !ok && owned(p) {
+			e.removed = append(e.removed, k)
+		}
+	}
+	for k, p := range target {
+		if cur, ok := m.policies[k]; owned(p) && (!ok || !reflect.DeepEqual(cur, p)) {
+			e.restored[k] = p
+		}
+	}
+	m.record(e)
+	return nil
+}
+
+// record applies a mutation to the current policies and appends it to the history
+// The caller must hold the write lock
+func (m *MemoryDriver) record(e entry) {
+	e.rev.ID = m.dropped + int64(len(m.history)) + 1
+	e.rev.Time = time.Now()
+
+	apply(m.policies, e)
+	if e.rev.ID%int64(m.opts.CheckpointEvery) == 0 {
+		e.checkpoint = copyPolicies(m.policies)
+	}
+	m.history = append(m.history, e)
+
+	if m.opts.MaxRevisions > 0 && len(m.history) > m.opts.MaxRevisions {
+		m.drop(len(m.history) - m.opts.MaxRevisions)
+	}
+}
+
+// drop folds the n oldest revisions into the base policies
+// The caller must hold the write lock
+func (m *MemoryDriver) drop(n int) {
+	for i := range m.history[:n] {
+		apply(m.base, m.history[i])
+		m.history[i] = entry{}
+	}
+	m.history = m.history[n:]
+	m.dropped += int64(n)
+}
+
+// replay rebuilds the policies as of a revision from the closest checkpoint
+// The caller must hold the read lock
+func (m *MemoryDriver) replay(rev int64) (map[string]policy.Policy, error) {
+	if rev < m.dropped && rev >= 0 {
+		return nil, fmt.Errorf("revision %d is no longer retained", rev)
+	}
+	if rev < 0 || rev > m.dropped+int64(len(m.history)) {
+		return nil, fmt.Errorf("unknown revision %d", rev)
+	}
+
+	entries := m.history[:rev-m.dropped]
+	start := m.base
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].checkpoint != nil {
+			start, entries = entries[i].checkpoint, entries[i+1:]
+			break
+		}
+	}
+
+	policies := copyPolicies(start)
+	for _, e := range entries {
+		apply(policies, e)
+	}
+	return policies, nil
+}
+
+// apply performs a mutation on policies
+func apply(policies map[string]policy.Policy, e entry) {
+	switch e.rev.Op {
+	case driver.OpSet:
+		policies[e.rev.Key] = e.policy
+	case driver.OpDelete:
+		delete(policies, e.rev.Key)
+	case driver.OpClear:
+		// Correctly clear the map
+		clear(policies)
+	case driver.OpClearTenant:
+		for k, p := range policies {
+			if p.Tenant == e.rev.Tenant {
+				delete(policies, k)
+			}
+		}
+	case driver.OpRollback:
+		for _, k := range e.removed {
+			delete(policies, k)
+		}
+		for k, p := range e.restored {
+			policies[k] = p
+		}
+	}
+}
+
+func copyPolicies(policies map[string]policy.Policy) map[string]policy.Policy {
+	cp := make(map[string]policy.Policy, len(policies))
+	for k, p := range policies {
+		cp[k] = p
+	}
+	return cp
+}
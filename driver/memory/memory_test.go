@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/driver"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDriver(t *testing.T) {
+	m := NewMemoryDriver()
+	read := policy.Policy{Subject: "user", Action: "read", Object: "article"}
+	write := policy.Policy{Subject: "user", Action: "write", Object: "article"}
+	require.NoError(t, m.Set(write))
+	require.NoError(t, m.Set(read))
+	assert.Error(t, m.Set(policy.Policy{Subject: "user"}))
+
+	got, ok := m.Get(read.Key())
+	require.True(t, ok)
+	assert.Equal(t, read, got)
+	assert.True(t, m.Exists(write.Key()))
+	assert.Equal(t, []string{read.Key(), write.Key()}, m.List())
+
+	require.NoError(t, m.Delete(write.Key()))
+	assert.False(t, m.Exists(write.Key()))
+
+	require.NoError(t, m.Clear())
+	assert.Empty(t, m.List())
+}
+
+func TestMemoryDriver_Find(t *testing.T) {
+	m := NewMemoryDriver()
+	for _, p := range []policy.Policy{
+		{Subject: "user", Action: "read", Object: "article"},
+		{Subject: "superuser", Action: "read", Object: "article"},
+		{Subject: "user", Action: "*", Object: "article"},
+		{Tenant: "acme", Subject: "user", Action: "read", Object: "article"},
+	} {
+		require.NoError(t, m.Set(p))
+	}
+
+	// Keys are matched whole, the wildcard policy is less specific
+	found, err := m.Find(policy.Policy{Subject: "user", Action: "read", Object: "article"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, policy.GlobalTenant, found[0].Tenant)
+	assert.Equal(t, "user", found[0].Subject)
+
+	found, err = m.Find(policy.Policy{Subject: "user", Action: "delete", Object: "article"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "*", found[0].Action)
+
+	_, err = m.Find(policy.Policy{Subject: "(", Action: "read", Object: "article"})
+	assert.Error(t, err)
+}
+
+func TestMemoryDriver_Tenants(t *testing.T) {
+	m := NewMemoryDriver()
+	_, ok := any(m).(driver.TenantDriver)
+	require.True(t, ok)
+
+	global := policy.Policy{Subject: "user", Action: "read", Object: "article"}
+	acme := policy.Policy{Tenant: "acme", Subject: "user", Action: "read", Object: "article"}
+	globex := policy.Policy{Tenant: "globex", Subject: "user", Action: "read", Object: "article"}
+	for _, p := range []policy.Policy{global, acme, globex} {
+		require.NoError(t, m.Set(p))
+	}
+
+	// Find only returns policies of the pattern's tenant
+	for _, tenant := range []string{policy.GlobalTenant, "acme", "globex"} {
+		found, err := m.Find(policy.Policy{Tenant: tenant, Subject: "user", Action: "read", Object: "article"})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, tenant, found[0].Tenant)
+	}
+	found, err := m.Find(policy.Policy{Tenant: "initech", Subject: "user", Action: "read", Object: "article"})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	// A global pattern never matches the policies of tenants
+	found, err = m.Find(policy.Policy{Subject: ".*", Action: "read", Object: "article"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, policy.GlobalTenant, found[0].Tenant)
+
+	assert.Equal(t, []string{acme.Key()}, m.ListTenant("acme"))
+	assert.Equal(t, []string{global.Key()}, m.ListTenant(policy.GlobalTenant))
+
+	require.NoError(t, m.ClearTenant("acme"))
+	assert.Equal(t, []string{globex.Key(), global.Key()}, m.List())
+}
+
+func TestMemoryDriver_History(t *testing.T) {
+	m := NewMemoryDriverWithOptions(Options{MaxRevisions: 3, CheckpointEvery: 2})
+	for _, action := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, m.SetAs("alice", policy.Policy{Subject: "user", Action: action, Object: "article"}))
+	}
+
+	revs := m.Revisions()
+	require.Len(t, revs, 3)
+	assert.Equal(t, int64(2), revs[0].ID)
+	assert.Equal(t, "alice", revs[0].Author)
+
+	snap, err := m.Snapshot(2)
+	require.NoError(t, err)
+	assert.Len(t, snap, 2)
+	_, err = m.Snapshot(0)
+	assert.ErrorContains(t, err, "no longer retained")
+	_, err = m.Snapshot(5)
+	assert.ErrorContains(t, err, "unknown revision")
+
+	require.NoError(t, m.Rollback("bob", 2))
+	assert.Len(t, m.List(), 2)
+	assert.Equal(t, driver.OpRollback, m.Revisions()[2].Op)
+}
+
+func TestMemoryDriver_DeleteMissing(t *testing.T) {
+	m := NewMemoryDriverWithOptions(Options{MaxRevisions: 2})
+	p := policy.Policy{Subject: "user", Action: "read", Object: "article"}
+	require.NoError(t, m.Set(p))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, m.DeleteAs("alice", "user:NULL:write:ALL:article:ANY"))
+	}
+	revs := m.Revisions()
+	require.Len(t, revs, 1)
+	assert.Equal(t, driver.OpSet, revs[0].Op)
+
+	// The set is still retained and can be rolled back to
+	require.NoError(t, m.Delete(p.Key()))
+	require.NoError(t, m.Rollback("alice", 1))
+	assert.True(t, m.Exists(p.Key()))
+}
@@ -0,0 +1,25 @@
+package driver
+
+import "fmt"
+
+// Migrate stores again every policy whose stored key differs from its current
+// policy.Key, e.g. keys written by an older key format, and returns how many
+// policies were moved
+func Migrate(d Driver) (int, error) {
+	moved := 0
+	for _, key := range d.List() {
+		p, ok := d.Get(key)
+		if !ok || p.Key() == key {
+			continue
+		}
+
+		if err := d.Set(p); err != nil {
+			return moved, fmt.Errorf("failed to migrate %q: %w", key, err)
+		}
+		if err := d.Delete(key); err != nil {
+			return moved, fmt.Errorf("failed to remove %q: %w", key, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
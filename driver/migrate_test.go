@@ -0,0 +1,90 @@
+package driver
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyedDriver stores policies under the keys it is seeded with, like a driver
+// written with an older key format
+type keyedDriver struct {
+	policies map[string]policy.Policy
+}
+
+func (d *keyedDriver) Set(p policy.Policy) error {
+	d.policies[p.Key()] = p
+	return nil
+}
+
+func (d *keyedDriver) Get(key string) (policy.Policy, bool) {
+	p, ok := d.policies[key]
+	return p, ok
+}
+
+func (d *keyedDriver) Find(policy.Policy) ([]policy.Policy, error) {
+	return nil, nil
+}
+
+func (d *keyedDriver) Delete(key string) error {
+	delete(d.policies, key)
+	return nil
+}
+
+func (d *keyedDriver) Exists(key string) bool {
+	_, ok := d.policies[key]
+	return ok
+}
+
+func (d *keyedDriver) Clear() error {
+	clear(d.policies)
+	return nil
+}
+
+func (d *keyedDriver) List() []string {
+	keys := make([]string, 0, len(d.policies))
+	for k := range d.policies {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestMigrate(t *testing.T) {
+	single := policy.Policy{Subject: "user", Action: "read:own", Object: "article"}
+	nested := policy.Policy{Subject: "user", Action: "read:own:draft", Object: "article:published:featured"}
+	tenant := policy.Policy{Tenant: "acme", Subject: "editor:team:a", Action: "update", Object: "article"}
+
+	d := &keyedDriver{policies: map[string]policy.Policy{
+		// Single-level keys did not change format
+		"user:NULL:read:own:article:ANY": single,
+		// Nested levels were joined with ':' before ScopeSeparator
+		"user:NULL:read:own:draft:article:published:featured": nested,
+		"acme@editor:team:a:update:ALL:article:ANY":           tenant,
+	}}
+
+	moved, err := Migrate(d)
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, []string{
+		"acme@editor:team.a:update:ALL:article:ANY",
+		"user:NULL:read:own.draft:article:published.featured",
+		"user:NULL:read:own:article:ANY",
+	}, d.List())
+	for _, key := range d.List() {
+		p, _ := d.Get(key)
+		assert.Equal(t, key, p.Key())
+
+		parsed, err := policy.ParseKey(key)
+		require.NoError(t, err)
+		assert.Equal(t, policy.Policy{Tenant: p.Tenant, Subject: p.Subject, Action: p.Action, Object: p.Object}, parsed)
+	}
+
+	// Migrated drivers have nothing left to move
+	moved, err = Migrate(d)
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+}
@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// PageRequest selects a page of List or Find results
+type PageRequest struct {
+	Cursor string // Next of the previous page, empty for the first page
+	Limit  int    // Maximum number of results, 0 for all remaining results
+}
+
+// Page holds one page of results
+type Page[T any] struct {
+	Items []T
+	Next  string // Cursor of the following page, empty on the last page
+}
+
+// Paginator is implemented by drivers that page through their policies
+// Pages are sorted by key, cursors stay valid when policies are added or
+// removed between pages
+type Paginator interface {
+	// ListPage is like List for a page of keys
+	ListPage(req PageRequest) (Page[string], error)
+
+	// ListTenantPage is like ListTenant for a page of keys
+	ListTenantPage(tenant string, req PageRequest) (Page[string], error)
+
+	// FindPage is like Find for a page of policies
+	FindPage(patternPolicy policy.Policy, req PageRequest) (Page[policy.Policy], error)
+}
+
+// ListPage is like Paginator.ListPage, paging through the sorted List of drivers
+// that do not implement Paginator
+func ListPage(d Driver, req PageRequest) (Page[string], error) {
+	if p, ok := d.(Paginator); ok {
+		return p.ListPage(req)
+	}
+	return Paginate(d.List(), keyOf, req)
+}
+
+// ListTenantPage is like Paginator.ListTenantPage, paging through the sorted
+// keys of ListTenant for drivers that do not implement Paginator
+func ListTenantPage(d Driver, tenant string, req PageRequest) (Page[string], error) {
+	if p, ok := d.(Paginator); ok {
+		return p.ListTenantPage(tenant, req)
+	}
+	return Paginate(ListTenant(d, tenant), keyOf, req)
+}
+
+// FindPage is like Paginator.FindPage, paging through the Find results sorted
+// by key of drivers that do not implement Paginator
+func FindPage(d Driver, patternPolicy policy.Policy, req PageRequest) (Page[policy.Policy], error) {
+	if p, ok := d.(Paginator); ok {
+		return p.FindPage(patternPolicy, req)
+	}
+	policies, err := d.Find(patternPolicy)
+	if err != nil {
+		return Page[policy.Policy]{}, err
+	}
+	return Paginate(policies, func(p policy.Policy) string { return p.Key() }, req)
+}
+
+func keyOf(k string) string {
+	return k
+}
+
+// Paginate sorts a copy of items by key and returns the page following the request's cursor
+// Drivers without native paging can build Paginator with it
+func Paginate[T any](items []T, key func(T) string, req PageRequest) (Page[T], error) {
+	if req.Limit < 0 {
+		return Page[T]{}, fmt.Errorf("page limit cannot be negative")
+	}
+
+	items = append([]T(nil), items...)
+	sort.SliceStable(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+
+	start := 0
+	if req.Cursor != "" {
+		last, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil {
+			return Page[T]{}, fmt.Errorf("invalid page cursor: %w", err)
+		}
+		// The first item after the last key of the previous page
+		start = sort.Search(len(items), func(i int) bool { return key(items[i]) > string(last) })
+	}
+
+	end := len(items)
+	if req.Limit > 0 && start+req.Limit < end {
+		end = start + req.Limit
+	}
+
+	page := Page[T]{Items: items[start:end:end]}
+	if end < len(items) {
+		page.Next = base64.RawURLEncoding.EncodeToString([]byte(key(items[end-1])))
+	}
+	return page, nil
+}
@@ -0,0 +1,116 @@
+package driver
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Op identifies the mutation recorded by a revision
+type Op string
+
+const (
+	OpSet         Op = "set"
+	OpDelete      Op = "delete"
+	OpClear       Op = "clear"
+	OpClearTenant Op = "clear-tenant"
+	OpRollback    Op = "rollback"
+)
+
+// Revision describes a single mutation of the stored policies
+type Revision struct {
+	ID     int64 // Starts at 1 and increases with every mutation
+	Author string
+	Time   time.Time
+	Op     Op
+	Key    string // Affected policy key for set and delete
+	Tenant string // Affected tenant for clear-tenant and tenant rollbacks
+	Target int64  // Restored revision for rollback
+}
+
+// Versioned is implemented by drivers that keep a history of their policies
+// Set, Delete, Clear and ClearTenant record revisions without an author
+type Versioned interface {
+	Driver
+
+	// SetAs is like Set but records the author of the revision
+	SetAs(author string, p policy.Policy) error
+
+	// DeleteAs is like Delete but records the author of the revision
+	DeleteAs(author string, key string) error
+
+	// ClearAs is like Clear but records the author of the revision
+	ClearAs(author string) error
+
+	// ClearTenantAs is like ClearTenant but records the author of the revision
+	ClearTenantAs(author string, tenant string) error
+
+	// Revisions lists the recorded revisions, oldest first
+	Revisions() []Revision
+
+	// Snapshot returns the policies as of a revision, 0 being the empty initial state,
+	// sorted by key
+	Snapshot(rev int64) ([]policy.Policy, error)
+
+	// FindAt is like Find but searches the policies as of a revision
+	FindAt(rev int64, patternPolicy policy.Policy) ([]policy.Policy, error)
+
+	// Rollback restores the policies of a revision, recording a new revision
+	Rollback(author string, rev int64) error
+
+	// RollbackTenant is like Rollback for the policies of one tenant, the
+	// policies of other tenants are kept; it is applied as a single revision
+	RollbackTenant(author string, tenant string, rev int64) error
+}
+
+// Change is a policy present in both sides of a diff with different content
+type Change struct {
+	Before policy.Policy
+	After  policy.Policy
+}
+
+// Diff lists the differences between two policy sets, ordered by key
+type Diff struct {
+	Added   []policy.Policy
+	Removed []policy.Policy
+	Changed []Change
+}
+
+// Empty reports whether the policy sets are identical
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Compare computes the differences between two policy sets, matching policies by key
+func Compare(before, after []policy.Policy) Diff {
+	old := make(map[string]policy.Policy, len(before))
+	for _, p := range before {
+		old[p.Key()] = p
+	}
+	cur := make(map[string]policy.Policy, len(after))
+	for _, p := range after {
+		cur[p.Key()] = p
+	}
+
+	var diff Diff
+	for key, p := range cur {
+		prev, ok := old[key]
+		if !ok {
+			diff.Added = append(diff.Added, p)
+		} else if !reflect.DeepEqual(prev, p) {
+			diff.Changed = append(diff.Changed, Change{Before: prev, After: p})
+		}
+	}
+	for key, p := range old {
+		if _, ok := cur[key]; !ok {
+			diff.Removed = append(diff.Removed, p)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Key() < diff.Added[j].Key() })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Key() < diff.Removed[j].Key() })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].After.Key() < diff.Changed[j].After.Key() })
+	return diff
+}
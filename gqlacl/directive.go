@@ -0,0 +1,138 @@
+package gqlacl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+)
+
+// Resolver resolves a field, like graphql.Resolver of gqlgen
+type Resolver func(ctx context.Context) (any, error)
+
+// PathFunc returns the response path of the field being resolved,
+// "users[0].posts[2].title", e.g. graphql.GetFieldContext(ctx).Path().String() with gqlgen
+type PathFunc func(ctx context.Context) string
+
+// RequestFunc returns the request of the current operation, e.g. its subjects
+// and attributes, the directive sets its Action and Object
+type RequestFunc func(ctx context.Context) (acl.Request, error)
+
+// ForbiddenError is returned for fields of an object the subjects cannot access
+type ForbiddenError struct {
+	Action string
+	Object string
+	Path   string
+}
+
+func (e *ForbiddenError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("forbidden: cannot %s %s", e.Action, e.Object)
+	}
+	return fmt.Sprintf("forbidden: cannot %s %s.%s", e.Action, e.Object, e.Path)
+}
+
+// Directive implements @acl(action: "read", object: "user") as a field middleware
+//
+// A denied check fails the field with a *ForbiddenError, a granted check
+// resolves the field to null when the Filters of the permission hide it
+type Directive struct {
+	ac      *acl.AccessControl
+	request RequestFunc
+}
+
+// NewDirective creates a directive checking fields against ac
+func NewDirective(ac *acl.AccessControl, request RequestFunc) *Directive {
+	return &Directive{ac: ac, request: request}
+}
+
+type cacheKey struct{}
+
+// cache holds the field decisions of an operation by action and object
+type cache struct {
+	mu     sync.Mutex
+	fields map[[2]string]*Fields
+}
+
+// WithCache returns ctx caching the checks of one operation, so each action and
+// object is checked once however many fields use it
+func WithCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheKey{}, &cache{fields: map[[2]string]*Fields{}})
+}
+
+// Field authorizes a field, path is its path relative to the object, see FieldPath
+// An empty path is the object itself, which only requires a granted check
+func (d *Directive) Field(ctx context.Context, action, object, path string, next Resolver) (any, error) {
+	fields, err := d.fields(ctx, action, object)
+	if err != nil {
+		return nil, err
+	}
+	if !fields.perm.Granted() {
+		return nil, &ForbiddenError{Action: action, Object: object, Path: path}
+	}
+
+	if path == "" {
+		return next(ctx)
+	}
+	ok, err := fields.Allowed(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	return next(ctx)
+}
+
+// Func returns the directive with the signature of gqlgen directive functions
+// for @acl(action: String!, object: String!), the path of each field is read with path
+// A graphql.Resolver can be passed as next as it is, gqlgen need not be imported here
+func (d *Directive) Func(path PathFunc) func(ctx context.Context, obj any, next func(context.Context) (any, error), action, object string) (any, error) {
+	return func(ctx context.Context, _ any, next func(context.Context) (any, error), action, object string) (any, error) {
+		return d.Field(ctx, action, object, FieldPath(path(ctx)), next)
+	}
+}
+
+// fields checks action and object, once per operation with WithCache
+func (d *Directive) fields(ctx context.Context, action, object string) (*Fields, error) {
+	c, _ := ctx.Value(cacheKey{}).(*cache)
+	if c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if f, ok := c.fields[[2]string{action, object}]; ok {
+			return f, nil
+		}
+	}
+
+	req, err := d.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Action, req.Object, req.Context = action, object, ctx
+	perm, err := d.ac.CheckRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewFields(perm)
+	if c != nil {
+		c.fields[[2]string{action, object}] = f
+	}
+	return f, nil
+}
+
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// FieldPath converts a response path, "users[0].posts[2].title" as printed by
+// gqlgen, to a path relative to the operation's root field: "posts[].title"
+func FieldPath(responsePath string) string {
+	path := listIndex.ReplaceAllString(responsePath, "[]")
+	_, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return ""
+	}
+	return rest
+}
@@ -0,0 +1,43 @@
+package gqlacl_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/gqlacl"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// resolver stands for graphql.Resolver, a named function type like gqlgen's
+type resolver func(ctx context.Context) (any, error)
+
+type pathKey struct{}
+
+func ExampleDirective_Func() {
+	ac, _ := acl.New([]policy.Policy{
+		{Subject: "guest", Action: "read", Object: "article", Filters: []string{"title"}},
+	}, acl.Options{}, memory.NewMemoryDriver())
+
+	dir := gqlacl.NewDirective(ac, func(context.Context) (acl.Request, error) {
+		return acl.Request{Subjects: []string{"guest"}}, nil
+	})
+
+	// With gqlgen: cfg.Directives.Acl = func(ctx context.Context, obj any, next graphql.Resolver, action, object string) (any, error) {
+	//     return aclFunc(ctx, obj, next, action, object)
+	// }
+	aclFunc := dir.Func(func(ctx context.Context) string {
+		return ctx.Value(pathKey{}).(string) // graphql.GetFieldContext(ctx).Path().String()
+	})
+
+	var next resolver = func(context.Context) (any, error) { return "value", nil }
+	for _, path := range []string{"article.title", "article.body"} {
+		ctx := context.WithValue(context.Background(), pathKey{}, path)
+		v, err := aclFunc(ctx, nil, next, "read", "article")
+		fmt.Println(path, v, err)
+	}
+	// Output:
+	// article.title value <nil>
+	// article.body <nil> <nil>
+}
@@ -0,0 +1,121 @@
+package gqlacl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alipourhabibi/abacl-go/permission"
+)
+
+// Fields decides which field paths a permission shows, the way Filter does for maps
+//
+// Paths are relative to the authorized object and use the notation of Filters:
+// dots for nested fields and "[]" for list fields, "author.email" or "comments[].body"
+type Fields struct {
+	perm *permission.Permission
+
+	mu    sync.Mutex
+	cache map[string]bool
+}
+
+// NewFields creates the field decisions of a permission
+func NewFields(perm *permission.Permission) *Fields {
+	return &Fields{perm: perm, cache: map[string]bool{}}
+}
+
+// Allowed reports whether a field path is visible
+func (f *Fields) Allowed(path string) (bool, error) {
+	allowed, err := f.Select([]string{path})
+	return len(allowed) == 1, err
+}
+
+// Select returns the visible paths among paths, preserving their order
+// An object field is visible when the Filters show any of its fields
+func (f *Fields) Select(paths []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	allowed := []string{}
+	for _, p := range paths {
+		ok, cached := f.cache[p]
+		if !cached {
+			var err error
+			if ok, err = f.decide(p); err != nil {
+				return nil, err
+			}
+			f.cache[p] = ok
+		}
+		if ok {
+			allowed = append(allowed, p)
+		}
+	}
+	return allowed, nil
+}
+
+// decide filters a document holding only the path and reports whether it remains
+// Fields are probed as empty objects, which the Filters keep when they show
+// the field or any field below it
+func (f *Fields) decide(path string) (bool, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return false, err
+	}
+
+	var probe any = map[string]any{}
+	for i := len(segs) - 1; i >= 0; i-- {
+		if segs[i].list {
+			probe = []any{probe}
+		}
+		probe = map[string]any{segs[i].name: probe}
+	}
+
+	visible, err := f.perm.Filter(probe)
+	if err != nil {
+		return false, fmt.Errorf("failed to filter fields: %w", err)
+	}
+	return visible != nil && present(visible, segs), nil
+}
+
+// segment is one field of a path, list fields hold their elements
+type segment struct {
+	name string
+	list bool
+}
+
+var fieldName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+func parsePath(path string) ([]segment, error) {
+	parts := strings.Split(path, ".")
+	segs := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		name, list := strings.CutSuffix(part, "[]")
+		if !fieldName.MatchString(name) {
+			return nil, fmt.Errorf("invalid field path %q", path)
+		}
+		segs = append(segs, segment{name: name, list: list})
+	}
+	return segs, nil
+}
+
+// present reports whether a path remains in a filtered probe
+func present(doc map[string]any, segs []segment) bool {
+	var v any = doc
+	for _, s := range segs {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if v, ok = m[s.name]; !ok {
+			return false
+		}
+		if list, ok := v.([]any); ok {
+			if len(list) == 0 {
+				return false
+			}
+			v = list[0]
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package gqlacl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newACL(t *testing.T) *acl.AccessControl {
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Filters: []string{"*", "!author.email", "!comments[].author"}},
+		{Subject: "guest", Action: "read", Object: "article", Filters: []string{"title", "author.name"}},
+		{Subject: "admin", Action: "read", Object: "article"},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+	return ac
+}
+
+func check(t *testing.T, ac *acl.AccessControl, subject string) *permission.Permission {
+	perm, err := ac.Check([]string{subject}, "read", "article")
+	require.NoError(t, err)
+	return perm
+}
+
+func TestFields_Select(t *testing.T) {
+	ac := newACL(t)
+	paths := []string{"title", "author", "author.name", "author.email", "comments[]", "comments[].body", "comments[].author"}
+
+	for _, tc := range []struct {
+		subject string
+		want    []string
+	}{
+		{"user", []string{"title", "author", "author.name", "comments[]", "comments[].body"}},
+		{"guest", []string{"title", "author", "author.name"}},
+		{"admin", paths},
+		{"nobody", []string{}},
+	} {
+		t.Run(tc.subject, func(t *testing.T) {
+			allowed, err := NewFields(check(t, ac, tc.subject)).Select(paths)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, allowed)
+		})
+	}
+
+	_, err := NewFields(check(t, ac, "user")).Allowed("author..name")
+	assert.ErrorContains(t, err, `invalid field path "author..name"`)
+}
+
+func TestFields_MatchesFilter(t *testing.T) {
+	ac := newACL(t)
+	doc := map[string]any{
+		"title":    "Hello",
+		"author":   map[string]any{"name": "Alice", "email": "alice@example.com"},
+		"comments": []any{map[string]any{"body": "Nice", "author": "bob"}},
+	}
+
+	for _, subject := range []string{"user", "guest", "admin"} {
+		perm := check(t, ac, subject)
+		filtered, err := perm.Filter(doc)
+		require.NoError(t, err)
+
+		fields := NewFields(perm)
+		for path, want := range map[string]bool{
+			"title":             filtered["title"] != nil,
+			"author.email":      lookup(filtered, "author", "email"),
+			"author.name":       lookup(filtered, "author", "name"),
+			"comments[].author": filtered["comments"] != nil && lookup(filtered["comments"].([]any)[0].(map[string]any), "author"),
+		} {
+			ok, err := fields.Allowed(path)
+			require.NoError(t, err)
+			assert.Equal(t, want, ok, "%s %s", subject, path)
+		}
+	}
+}
+
+func lookup(doc map[string]any, path ...string) bool {
+	var v any = doc
+	for _, p := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if v, ok = m[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type subjectKey struct{}
+
+func TestDirective_Field(t *testing.T) {
+	ac := newACL(t)
+	checks := 0
+	dir := NewDirective(ac, func(ctx context.Context) (acl.Request, error) {
+		checks++
+		return acl.Request{Subjects: []string{ctx.Value(subjectKey{}).(string)}}, nil
+	})
+	resolve := func(v any) Resolver {
+		return func(context.Context) (any, error) { return v, nil }
+	}
+
+	// Resolving { article { title author { name email } comments { author } } } as guest
+	ctx := WithCache(context.WithValue(context.Background(), subjectKey{}, "guest"))
+	result := map[string]any{}
+	for _, path := range []string{"article", "article.title", "article.author.name", "article.author.email", "article.comments[0].author"} {
+		v, err := dir.Field(ctx, "read", "article", FieldPath(path), resolve("value"))
+		require.NoError(t, err)
+		result[path] = v
+	}
+	assert.Equal(t, map[string]any{
+		"article":                    "value",
+		"article.title":              "value",
+		"article.author.name":        "value",
+		"article.author.email":       nil,
+		"article.comments[0].author": nil,
+	}, result)
+	assert.Equal(t, 1, checks)
+
+	ctx = context.WithValue(context.Background(), subjectKey{}, "nobody")
+	_, err := dir.Field(ctx, "read", "article", "title", resolve("value"))
+	var forbidden *ForbiddenError
+	require.True(t, errors.As(err, &forbidden))
+	assert.Equal(t, "forbidden: cannot read article.title", err.Error())
+}
+
+func TestFieldPath(t *testing.T) {
+	assert.Equal(t, "", FieldPath("users"))
+	assert.Equal(t, "", FieldPath("users[3]"))
+	assert.Equal(t, "name", FieldPath("users[3].name"))
+	assert.Equal(t, "posts[].comments[].body", FieldPath("user.posts[2].comments[10].body"))
+}
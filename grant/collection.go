@@ -0,0 +1,218 @@
+package grant
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alipourhabibi/gonotation/v2/notation"
+)
+
+// FieldList applies field filters to every element of a list, preserving order
+// The list can be any slice, e.g. []map[string]any or []Article
+func (g *Grant) FieldList(data any) ([]map[string]any, error) {
+	if len(g.policies) == 0 {
+		return g.mergeList(data, [][]string{{"*"}})
+	}
+	return g.mergeList(data, fieldGlobs(g.policies))
+}
+
+// FilterList applies data filters to every element of a list, preserving order
+func (g *Grant) FilterList(data any) ([]map[string]any, error) {
+	if !hasFilters(g.policies) {
+		return g.mergeList(data, [][]string{{"*"}})
+	}
+	return g.mergeList(data, filterGlobs(g.policies))
+}
+
+// filterDocument applies globs to a document, supporting array paths such as
+// "comments[].authorEmail" which apply the rest of the path to each element
+func filterDocument(doc map[string]any, globs []string) (map[string]any, error) {
+	if !hasArrayPath(globs) {
+		return notation.FilterMap(doc, globs)
+	}
+
+	// Globs without array paths keep the notation semantics,
+	// the others are grouped by their first path segment
+	plain := []string{}
+	heads := []string{}
+	nested := map[string][]string{}
+
+	for _, glob := range globs {
+		neg := strings.HasPrefix(glob, "!")
+		path := strings.TrimPrefix(glob, "!")
+		if !strings.Contains(path, "[]") {
+			plain = append(plain, glob)
+			continue
+		}
+
+		head, rest := splitPath(path)
+		if rest == "" {
+			// "tags[]" is the same as "tags"
+			plain = append(plain, strings.TrimSuffix(glob, "[]"))
+			continue
+		}
+		if neg {
+			rest = "!" + rest
+		}
+		if _, ok := nested[head]; !ok {
+			heads = append(heads, head)
+		}
+		nested[head] = append(nested[head], rest)
+	}
+
+	result := map[string]any{}
+	if len(plain) > 0 {
+		filtered, err := notation.FilterMap(doc, plain)
+		if err != nil {
+			return nil, err
+		}
+		result = filtered
+	}
+
+	for _, head := range heads {
+		value, ok := doc[head]
+		if !ok {
+			continue
+		}
+
+		includes := []string{}
+		excludes := []string{}
+		for _, sub := range nested[head] {
+			if strings.HasPrefix(sub, "!") {
+				excludes = append(excludes, sub)
+			} else {
+				includes = append(includes, sub)
+			}
+		}
+
+		if _, ok := result[head]; ok {
+			// Already included as a whole, only exclusions narrow it
+			if len(excludes) == 0 {
+				continue
+			}
+			filtered, err := filterValue(result[head], append([]string{"*"}, excludes...))
+			if err != nil {
+				return nil, err
+			}
+			result[head] = filtered
+		} else if len(includes) > 0 {
+			filtered, err := filterValue(value, append(includes, excludes...))
+			if err != nil {
+				return nil, err
+			}
+			result[head] = filtered
+		}
+	}
+
+	return result, nil
+}
+
+// filterValue applies globs to a nested document or to each element of a nested list
+func filterValue(value any, globs []string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return filterDocument(v, globs)
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			filtered, err := filterValue(item, globs)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, filtered)
+		}
+		return result, nil
+	case []map[string]any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			filtered, err := filterDocument(item, globs)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, filtered)
+		}
+		return result, nil
+	}
+
+	// Scalars have no fields to filter
+	return value, nil
+}
+
+// splitPath splits a path at its first segment, dropping the array marker
+// "comments[].author.email" becomes "comments" and "author.email"
+func splitPath(path string) (string, string) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, ""
+	}
+
+	head := path[:end]
+	rest := strings.TrimPrefix(path[end:], "[]")
+	rest = strings.TrimPrefix(rest, ".")
+	return head, rest
+}
+
+func hasArrayPath(globs []string) bool {
+	for _, glob := range globs {
+		if strings.Contains(glob, "[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// toDocument converts data to a generic document the same way notation does
+func toDocument(data any) (map[string]any, error) {
+	doc := map[string]any{}
+	switch d := data.(type) {
+	case map[string]any:
+		return d, nil
+	case string:
+		err := json.Unmarshal([]byte(d), &doc)
+		return doc, err
+	case []byte:
+		err := json.Unmarshal(d, &doc)
+		return doc, err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &doc)
+	return doc, err
+}
+
+// toList converts any slice to a list of generic values
+func toList(data any) ([]any, error) {
+	switch d := data.(type) {
+	case []any:
+		return d, nil
+	case []map[string]any:
+		items := make([]any, len(d))
+		for i, m := range d {
+			items[i] = m
+		}
+		return items, nil
+	}
+
+	items := []any{}
+	var b []byte
+	switch d := data.(type) {
+	case string:
+		b = []byte(d)
+	case []byte:
+		b = d
+	default:
+		var err error
+		if b, err = json.Marshal(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("data is not a list: %w", err)
+	}
+	return items, nil
+}
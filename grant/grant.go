@@ -2,6 +2,8 @@ package grant
 
 import (
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/alipourhabibi/abacl-go/policy"
@@ -10,17 +12,33 @@ import (
 
 // Grant represents a collection of policies that were matched for an access check
 type Grant struct {
-	strict   bool
-	policies []policy.Policy
-	present  map[string]policy.Policy
+	strict    bool
+	mergeMode Merge
+	policies  []policy.Policy
+	present   map[string]policy.Policy
+}
+
+// Options configures a Grant
+type Options struct {
+	// Strict mode requires exact scope matching
+	Strict bool
+
+	// Merge selects how the Fields and Filters of several matched policies are combined
+	Merge Merge
 }
 
 // New creates a new Grant from the given policies
 func New(policies []policy.Policy, strict bool) (*Grant, error) {
+	return NewWithOptions(policies, Options{Strict: strict})
+}
+
+// NewWithOptions is like New but allows choosing the merge mode
+func NewWithOptions(policies []policy.Policy, opts Options) (*Grant, error) {
 	grant := &Grant{
-		policies: policies,
-		strict:   strict,
-		present:  make(map[string]policy.Policy),
+		policies:  policies,
+		strict:    opts.Strict,
+		mergeMode: opts.Merge,
+		present:   make(map[string]policy.Policy),
 	}
 
 	// Build present map for quick lookups
@@ -31,6 +49,11 @@ func New(policies []policy.Policy, strict bool) (*Grant, error) {
 	return grant, nil
 }
 
+// Options returns the options the grant was created with
+func (g *Grant) Options() Options {
+	return Options{Strict: g.strict, Merge: g.mergeMode}
+}
+
 // GetPresent returns the present map
 func (g *Grant) GetPresent() map[string]policy.Policy {
 	return g.present
@@ -58,16 +81,27 @@ func (g *Grant) Delete(p policy.Policy) {
 }
 
 // Get finds policies matching the given policy pattern (using regex)
+// Wildcard policies match like in the drivers, only the most specific matches
+// are returned, in policy.Sort order
 func (g *Grant) Get(pol policy.Policy) ([]policy.Policy, bool) {
 	key := pol.Key()
 	pols := []policy.Policy{}
 
-	for k := range g.present {
+	for k, p := range g.present {
+		if p.IsPattern() {
+			inst, ok := p.Instantiate(pol)
+			if !ok {
+				continue
+			}
+			k = inst.Key()
+		}
 		ok, _ := regexp.MatchString(key, k)
 		if ok {
-			pols = append(pols, g.present[k])
+			pols = append(pols, p)
 		}
 	}
+	pols = policy.MostSpecific(pols)
+	policy.Sort(pols)
 	return pols, len(pols) != 0
 }
 
@@ -119,11 +153,12 @@ func (g *Grant) Objects() []string {
 // parse extracts main and scope from a component string
 func (g *Grant) parse(prop string) []string {
 	// 0: main
-	// 1: scope
-	return strings.Split(prop, ":")
+	// 1: scope, nested levels stay joined: "own:draft"
+	return strings.SplitN(prop, ":", 2)
 }
 
 // Scopes extracts unique scopes from a specific component (subject, action, or object)
+// Scopes are sorted
 func (g *Grant) Scopes(prop string) []string {
 	scopeSet := []string{}
 
@@ -147,38 +182,39 @@ func (g *Grant) Scopes(prop string) []string {
 		}
 	}
 
-	return scopeSet
+	sort.Strings(scopeSet)
+	return slices.Compact(scopeSet)
 }
 
 // Field applies field filters from all policies to the given data
+// The views of the policies are combined with the grant's Merge mode
 func (g *Grant) Field(data any) (map[string]any, error) {
-	fields := []string{}
-	for _, p := range g.policies {
-		fields = append(fields, p.Fields...)
-	}
-
-	if len(fields) == 0 {
+	if len(g.policies) == 0 {
 		return notation.FilterMap(data, []string{"*"})
 	}
 
-	return notation.FilterMap(data, fields)
+	doc, err := toDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return g.merge(doc, fieldGlobs(g.policies))
 }
 
 // Filter applies data filters from policies
+// The views of the policies are combined with the grant's Merge mode
 func (g *Grant) Filter(data any) (map[string]any, error) {
-	filters := []string{}
-	for _, p := range g.policies {
-		filters = append(filters, p.Filters...)
-	}
-
-	if len(filters) == 0 {
+	if !hasFilters(g.policies) {
 		if m, ok := data.(map[string]any); ok {
 			return m, nil
 		}
 		return notation.FilterMap(data, []string{"*"})
 	}
 
-	return notation.FilterMap(data, filters)
+	doc, err := toDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return g.merge(doc, filterGlobs(g.policies))
 }
 
 // CacheKey represents a query key for filtering policies
@@ -192,64 +228,50 @@ type CacheKey struct {
 // FieldByCKey filters data based on field permissions matching a specific cache key
 // This is your original implementation
 func (g *Grant) FieldByCKey(data any, cKey CacheKey) (map[string]any, error) {
-	if g.present == nil {
-		g.present = make(map[string]policy.Policy)
-	}
-
-	// Ensure present map is populated
-	for _, p := range g.policies {
-		g.Update(p)
+	newPols := g.matchCKey(cKey)
+	if len(newPols) == 0 {
+		return map[string]any{}, nil
 	}
 
-	// Find matching policies
-	var newPols []policy.Policy
-	cKeyPol := policy.Policy{
-		Subject: cKey.Subject,
-		Object:  cKey.Object,
-		Action:  cKey.Action,
+	doc, err := toDocument(data)
+	if err != nil {
+		return nil, err
 	}
+	return g.merge(doc, fieldGlobs(newPols))
+}
 
-	if !cKey.Strict {
-		p := cKeyPol.Strictify()
-		newPols, _ = g.Get(p)
-	} else {
-		newPols, _ = g.Get(cKeyPol)
+// FilterByCKey applies data filters matching a specific cache key
+func (g *Grant) FilterByCKey(data any, cKey CacheKey) (map[string]any, error) {
+	newPols := g.matchCKey(cKey)
+	if len(newPols) == 0 {
+		return map[string]any{}, nil
 	}
 
-	// Collect field patterns from matching policies
-	mapDatas := map[string]any{}
-	for _, p := range newPols {
-		globs := []string{}
-		if len(p.Fields) == 0 {
-			globs = append(globs, "*")
-		} else {
-			globs = append(globs, p.Fields...)
-		}
-
-		data, err := notation.FilterMap(data, globs)
-		if err != nil {
-			return nil, err
-		}
-
-		for k, vv := range data {
-			mapDatas[k] = vv
+	// No filters means allow all
+	if !hasFilters(newPols) {
+		if m, ok := data.(map[string]any); ok {
+			return m, nil
 		}
 	}
 
-	return mapDatas, nil
+	doc, err := toDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return g.merge(doc, filterGlobs(newPols))
 }
 
-// FilterByCKey applies data filters matching a specific cache key
-func (g *Grant) FilterByCKey(data any, cKey CacheKey) (map[string]any, error) {
+// matchCKey finds the policies matching a cache key
+func (g *Grant) matchCKey(cKey CacheKey) []policy.Policy {
 	if g.present == nil {
 		g.present = make(map[string]policy.Policy)
 	}
 
+	// Ensure present map is populated
 	for _, p := range g.policies {
 		g.Update(p)
 	}
 
-	// Find matching policies
 	var newPols []policy.Policy
 	cKeyPol := policy.Policy{
 		Subject: cKey.Subject,
@@ -264,29 +286,79 @@ func (g *Grant) FilterByCKey(data any, cKey CacheKey) (map[string]any, error) {
 		newPols, _ = g.Get(cKeyPol)
 	}
 
-	// Collect filter patterns
-	mapDatas := map[string]any{}
-	for _, p := range newPols {
-		globs := []string{}
+	return newPols
+}
+
+// fieldGlobs returns the field globs of every policy, no fields means all fields
+func fieldGlobs(policies []policy.Policy) [][]string {
+	sets := make([][]string, 0, len(policies))
+	for _, p := range policies {
+		if len(p.Fields) == 0 {
+			sets = append(sets, []string{"*"})
+		} else {
+			sets = append(sets, p.Fields)
+		}
+	}
+	return sets
+}
+
+// filterGlobs returns the filter globs of every policy, no filters means all fields
+func filterGlobs(policies []policy.Policy) [][]string {
+	sets := make([][]string, 0, len(policies))
+	for _, p := range policies {
 		if len(p.Filters) == 0 {
-			// No filters means allow all
-			if m, ok := data.(map[string]any); ok {
-				return m, nil
-			}
-			globs = append(globs, "*")
+			sets = append(sets, []string{"*"})
 		} else {
-			globs = append(globs, p.Filters...)
+			sets = append(sets, p.Filters)
+		}
+	}
+	return sets
+}
+
+func hasFilters(policies []policy.Policy) bool {
+	for _, p := range policies {
+		if len(p.Filters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRow reports whether any policy in the grant permits the record
+// All conditions of a policy must hold, a policy without conditions permits every row
+func (g *Grant) MatchRow(record map[string]any, attrs map[string]any) (bool, error) {
+	for _, p := range g.policies {
+		matched := true
+		for _, c := range p.Conditions {
+			ok, err := c.Match(record, attrs)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
 		}
+	}
+
+	return false, nil
+}
 
-		filtered, err := notation.FilterMap(data, globs)
+// Rows returns the records permitted by the grant, preserving their order
+func (g *Grant) Rows(records []map[string]any, attrs map[string]any) ([]map[string]any, error) {
+	rows := []map[string]any{}
+	for _, r := range records {
+		ok, err := g.MatchRow(r, attrs)
 		if err != nil {
 			return nil, err
 		}
-
-		for k, vv := range filtered {
-			mapDatas[k] = vv
+		if ok {
+			rows = append(rows, r)
 		}
 	}
 
-	return mapDatas, nil
+	return rows, nil
 }
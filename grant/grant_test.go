@@ -1,7 +1,10 @@
 package grant
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/alipourhabibi/abacl-go/policy"
 	"github.com/stretchr/testify/assert"
@@ -453,3 +456,537 @@ func TestGrant_SubjectsActionsObjects(t *testing.T) {
 		assert.Contains(t, objects, "comment")
 	})
 }
+
+func TestGrant_FilterList(t *testing.T) {
+	policies := []policy.Policy{
+		{
+			Subject: "user",
+			Action:  "read",
+			Object:  "article",
+			Filters: []string{"*", "!password"},
+		},
+	}
+
+	g, err := New(policies, false)
+	require.NoError(t, err)
+
+	t.Run("list of maps keeps order", func(t *testing.T) {
+		data := []map[string]any{
+			{"id": 3, "title": "C", "password": "x"},
+			{"id": 1, "title": "A", "password": "y"},
+			{"id": 2, "title": "B", "password": "z"},
+		}
+
+		filtered, err := g.FilterList(data)
+		require.NoError(t, err)
+		require.Len(t, filtered, 3)
+		assert.Equal(t, map[string]any{"id": 3, "title": "C"}, filtered[0])
+		assert.Equal(t, map[string]any{"id": 1, "title": "A"}, filtered[1])
+		assert.Equal(t, map[string]any{"id": 2, "title": "B"}, filtered[2])
+	})
+
+	t.Run("list of structs", func(t *testing.T) {
+		type article struct {
+			ID       int    `json:"id"`
+			Title    string `json:"title"`
+			Password string `json:"password"`
+		}
+		data := []article{{ID: 1, Title: "A", Password: "x"}, {ID: 2, Title: "B", Password: "y"}}
+
+		filtered, err := g.FilterList(data)
+		require.NoError(t, err)
+		require.Len(t, filtered, 2)
+		assert.Equal(t, "A", filtered[0]["title"])
+		assert.Equal(t, "B", filtered[1]["title"])
+		assert.NotContains(t, filtered[0], "password")
+		assert.NotContains(t, filtered[1], "password")
+	})
+
+	t.Run("not a list", func(t *testing.T) {
+		_, err := g.FilterList(map[string]any{"id": 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestGrant_ArrayPaths(t *testing.T) {
+	data := map[string]any{
+		"id":    1,
+		"title": "Article",
+		"comments": []any{
+			map[string]any{"body": "first", "authorEmail": "a@example.com", "author": "a"},
+			map[string]any{"body": "second", "authorEmail": "b@example.com", "author": "b"},
+		},
+		"meta": map[string]any{
+			"revisions": []map[string]any{
+				{"rev": 1, "editor": "a", "diff": "..."},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		expected map[string]any
+	}{
+		{
+			name:    "exclude field of every element",
+			filters: []string{"*", "!meta", "!comments[].authorEmail"},
+			expected: map[string]any{
+				"id":    1,
+				"title": "Article",
+				"comments": []any{
+					map[string]any{"body": "first", "author": "a"},
+					map[string]any{"body": "second", "author": "b"},
+				},
+			},
+		},
+		{
+			name:    "include fields of every element",
+			filters: []string{"title", "comments[].body", "comments[].author"},
+			expected: map[string]any{
+				"title": "Article",
+				"comments": []any{
+					map[string]any{"body": "first", "author": "a"},
+					map[string]any{"body": "second", "author": "b"},
+				},
+			},
+		},
+		{
+			name:    "nested list inside document",
+			filters: []string{"id", "meta.revisions[].rev"},
+			expected: map[string]any{
+				"id": 1,
+				"meta": map[string]any{
+					"revisions": []any{map[string]any{"rev": 1}},
+				},
+			},
+		},
+		{
+			name:     "array marker without path",
+			filters:  []string{"id", "comments[]"},
+			expected: map[string]any{"id": 1, "comments": data["comments"]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New([]policy.Policy{
+				{Subject: "user", Action: "read", Object: "article", Filters: tt.filters},
+			}, false)
+			require.NoError(t, err)
+
+			filtered, err := g.Filter(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filtered)
+
+			list, err := g.FilterList([]map[string]any{data, data})
+			require.NoError(t, err)
+			assert.Equal(t, []map[string]any{tt.expected, tt.expected}, list)
+		})
+	}
+}
+
+func TestGrant_FilterInto(t *testing.T) {
+	type author struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type comment struct {
+		Body   string  `json:"body"`
+		Author *author `json:"author"`
+	}
+	type audit struct {
+		CreatedBy string `json:"createdBy"`
+	}
+	type article struct {
+		audit
+		ID       int               `json:"id"`
+		Title    string            `json:"title"`
+		Password string            `json:"password,omitempty"`
+		Author   author            `json:"author"`
+		Editor   *author           `json:"editor"`
+		Comments []comment         `json:"comments"`
+		Labels   map[string]string `json:"labels"`
+		Payload  any               `json:"payload"`
+		Internal string            `json:"-"`
+	}
+
+	in := article{
+		audit:    audit{CreatedBy: "system"},
+		ID:       1,
+		Title:    "Test",
+		Password: "secret",
+		Author:   author{Name: "John", Email: "john@example.com"},
+		Editor:   &author{Name: "Jane", Email: "jane@example.com"},
+		Comments: []comment{{Body: "nice", Author: &author{Name: "Bob", Email: "bob@example.com"}}},
+		Labels:   map[string]string{"public": "yes", "secret": "no"},
+		Payload:  map[string]any{"ref": "x1", "token": "t0k3n", "owner": map[string]any{"name": "John", "email": "john@example.com"}},
+		Internal: "internal",
+	}
+
+	g, err := New([]policy.Policy{
+		{
+			Subject: "user",
+			Action:  "read",
+			Object:  "article",
+			Filters: []string{"*", "!password", "!author.email", "!editor.email", "!comments[].author.email", "!labels.secret", "!createdBy", "!payload.token", "!payload.owner.email"},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	out, err := FilterInto(g, in)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, out.ID)
+	assert.Equal(t, "Test", out.Title)
+	assert.Empty(t, out.Password)
+	assert.Empty(t, out.CreatedBy)
+	assert.Empty(t, out.Internal)
+	assert.Equal(t, author{Name: "John"}, out.Author)
+	assert.Equal(t, &author{Name: "Jane"}, out.Editor)
+	assert.Equal(t, []comment{{Body: "nice", Author: &author{Name: "Bob"}}}, out.Comments)
+	assert.Equal(t, map[string]string{"public": "yes"}, out.Labels)
+	assert.Equal(t, map[string]any{"ref": "x1", "owner": map[string]any{"name": "John"}}, out.Payload)
+
+	// The input must be left untouched
+	assert.Equal(t, "secret", in.Password)
+	assert.Equal(t, "jane@example.com", in.Editor.Email)
+	assert.Equal(t, "bob@example.com", in.Comments[0].Author.Email)
+	assert.Equal(t, "system", in.CreatedBy)
+	assert.Len(t, in.Labels, 2)
+	assert.Len(t, in.Payload, 3)
+
+	t.Run("pointer input", func(t *testing.T) {
+		out, err := FilterInto(g, &in)
+		require.NoError(t, err)
+		assert.NotSame(t, &in, out)
+		assert.Empty(t, out.Password)
+		assert.Equal(t, "secret", in.Password)
+	})
+
+	t.Run("field whitelist", func(t *testing.T) {
+		g, err := New([]policy.Policy{
+			{Subject: "user", Action: "update", Object: "article", Fields: []string{"title", "author.name"}},
+		}, false)
+		require.NoError(t, err)
+
+		out, err := FieldInto(g, in)
+		require.NoError(t, err)
+		assert.Equal(t, article{Title: "Test", Author: author{Name: "John"}}, out)
+	})
+}
+
+// money writes its own JSON form, which does not follow its fields
+type money struct {
+	Amount int
+	Cur    string
+}
+
+func (m money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%d %s", m.Amount, m.Cur))
+}
+
+func TestGrant_FieldInto_Marshalers(t *testing.T) {
+	type item struct {
+		Price   money     `json:"price"`
+		Cost    *money    `json:"cost"`
+		Created time.Time `json:"created"`
+		Secret  string    `json:"secret"`
+	}
+	in := item{
+		Price:   money{Amount: 5, Cur: "USD"},
+		Cost:    &money{Amount: 3, Cur: "USD"},
+		Created: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Secret:  "s",
+	}
+
+	g, err := New([]policy.Policy{
+		{Subject: "user", Action: "update", Object: "item", Fields: []string{"*", "!secret"}},
+	}, false)
+	require.NoError(t, err)
+
+	out, err := FieldInto(g, in)
+	require.NoError(t, err)
+	assert.Equal(t, item{Price: in.Price, Cost: in.Cost, Created: in.Created}, out)
+
+	g, err = New([]policy.Policy{
+		{Subject: "user", Action: "update", Object: "item", Fields: []string{"*", "!price", "!created"}},
+	}, false)
+	require.NoError(t, err)
+
+	out, err = FieldInto(g, in)
+	require.NoError(t, err)
+	assert.Equal(t, item{Cost: in.Cost, Secret: "s"}, out)
+}
+
+func TestGrant_ValidateFields(t *testing.T) {
+	policies := []policy.Policy{
+		{
+			Subject: "user",
+			Action:  "update",
+			Object:  "article",
+			Fields:  []string{"*", "!id", "!meta.createdAt", "!comments[].author"},
+		},
+	}
+
+	g, err := New(policies, false)
+	require.NoError(t, err)
+
+	t.Run("allowed write", func(t *testing.T) {
+		err := g.ValidateFields(map[string]any{
+			"title": "New",
+			"meta":  map[string]any{"tags": []any{"go"}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("forbidden fields are listed", func(t *testing.T) {
+		data := map[string]any{
+			"id":    999,
+			"title": "New",
+			"meta":  map[string]any{"createdAt": "2025-01-01", "tags": []any{"go"}},
+			"comments": []any{
+				map[string]any{"body": "ok"},
+				map[string]any{"body": "hi", "author": "mallory"},
+			},
+		}
+
+		err := g.ValidateFields(data)
+		require.Error(t, err)
+
+		var fe *ForbiddenFieldsError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, []string{"comments[1].author", "id", "meta.createdAt"}, fe.Paths)
+
+		// The request data must not be modified by validation
+		assert.Contains(t, data["meta"], "createdAt")
+	})
+
+	t.Run("whitelist", func(t *testing.T) {
+		g, err := New([]policy.Policy{
+			{Subject: "user", Action: "update", Object: "profile", Fields: []string{"name", "address.city"}},
+		}, false)
+		require.NoError(t, err)
+
+		err = g.ValidateFields(map[string]any{
+			"name":    "John",
+			"role":    "admin",
+			"address": map[string]any{"city": "Berlin", "zip": "10115"},
+		})
+		var fe *ForbiddenFieldsError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, []string{"address.zip", "role"}, fe.Paths)
+	})
+}
+
+func TestGrant_Merge(t *testing.T) {
+	roles := map[string]policy.Policy{
+		"staff":   {Subject: "staff", Action: "read", Object: "employee", Fields: []string{"*", "!salary"}, Filters: []string{"*", "!salary"}},
+		"hr":      {Subject: "hr", Action: "read", Object: "employee", Fields: []string{"name", "salary"}, Filters: []string{"name", "salary"}},
+		"public":  {Subject: "public", Action: "read", Object: "employee", Fields: []string{"name"}, Filters: []string{"name"}},
+		"admin":   {Subject: "admin", Action: "read", Object: "employee"},
+		"contact": {Subject: "contact", Action: "read", Object: "employee", Fields: []string{"name", "address.city"}, Filters: []string{"name", "address.city"}},
+	}
+
+	data := map[string]any{
+		"name":    "John",
+		"email":   "john@example.com",
+		"salary":  100,
+		"address": map[string]any{"city": "Berlin", "street": "Main St"},
+	}
+	all := data
+	noSalary := map[string]any{"name": "John", "email": "john@example.com", "address": map[string]any{"city": "Berlin", "street": "Main St"}}
+	nameOnly := map[string]any{"name": "John"}
+	nameCity := map[string]any{"name": "John", "address": map[string]any{"city": "Berlin"}}
+
+	tests := []struct {
+		name     string
+		roles    []string
+		expected map[Merge]map[string]any
+	}{
+		{
+			name:  "single role",
+			roles: []string{"staff"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           noSalary,
+				MergeIntersection:    noSalary,
+				MergeMostRestrictive: noSalary,
+			},
+		},
+		{
+			name:  "blacklist and whitelist",
+			roles: []string{"staff", "public"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           noSalary,
+				MergeIntersection:    nameOnly,
+				MergeMostRestrictive: nameOnly,
+			},
+		},
+		{
+			name:  "order does not matter",
+			roles: []string{"public", "staff"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           noSalary,
+				MergeIntersection:    nameOnly,
+				MergeMostRestrictive: nameOnly,
+			},
+		},
+		{
+			name:  "blacklist and whitelist of the excluded field",
+			roles: []string{"staff", "hr"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           all,
+				MergeIntersection:    nameOnly,
+				MergeMostRestrictive: map[string]any{"name": "John", "salary": 100},
+			},
+		},
+		{
+			name:  "unrestricted role",
+			roles: []string{"admin", "public"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           all,
+				MergeIntersection:    nameOnly,
+				MergeMostRestrictive: nameOnly,
+			},
+		},
+		{
+			name:  "nested fields",
+			roles: []string{"staff", "contact"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           noSalary,
+				MergeIntersection:    nameCity,
+				MergeMostRestrictive: nameCity,
+			},
+		},
+		{
+			name:  "three roles",
+			roles: []string{"hr", "contact", "public"},
+			expected: map[Merge]map[string]any{
+				MergeUnion:           map[string]any{"name": "John", "salary": 100, "address": map[string]any{"city": "Berlin"}},
+				MergeIntersection:    nameOnly,
+				MergeMostRestrictive: nameOnly,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []Merge{MergeUnion, MergeIntersection, MergeMostRestrictive} {
+			t.Run(tt.name+"/"+mode.String(), func(t *testing.T) {
+				policies := []policy.Policy{}
+				for _, r := range tt.roles {
+					policies = append(policies, roles[r])
+				}
+
+				g, err := NewWithOptions(policies, Options{Merge: mode})
+				require.NoError(t, err)
+				expected := tt.expected[mode]
+
+				field, err := g.Field(data)
+				require.NoError(t, err)
+				assert.Equal(t, expected, field, "Field")
+
+				filter, err := g.Filter(data)
+				require.NoError(t, err)
+				assert.Equal(t, expected, filter, "Filter")
+
+				fieldList, err := g.FieldList([]map[string]any{data})
+				require.NoError(t, err)
+				assert.Equal(t, []map[string]any{expected}, fieldList, "FieldList")
+
+				filterList, err := g.FilterList([]map[string]any{data})
+				require.NoError(t, err)
+				assert.Equal(t, []map[string]any{expected}, filterList, "FilterList")
+
+				// Every role shares the same action and object, so a non-strict
+				// cache key per role matches only that role's policy
+				for _, r := range tt.roles {
+					cKey := CacheKey{Subject: r, Action: "read", Object: "employee"}
+					single, err := NewWithOptions([]policy.Policy{roles[r]}, Options{Merge: mode})
+					require.NoError(t, err)
+
+					want, err := single.Field(data)
+					require.NoError(t, err)
+					got, err := g.FieldByCKey(data, cKey)
+					require.NoError(t, err)
+					assert.Equal(t, want, got, "FieldByCKey %s", r)
+
+					want, err = single.Filter(data)
+					require.NoError(t, err)
+					got, err = g.FilterByCKey(data, cKey)
+					require.NoError(t, err)
+					assert.Equal(t, want, got, "FilterByCKey %s", r)
+				}
+			})
+		}
+	}
+
+	// The input must be left untouched by every mode
+	assert.Equal(t, map[string]any{"city": "Berlin", "street": "Main St"}, data["address"])
+}
+
+func TestGrant_MergeByCKey(t *testing.T) {
+	policies := []policy.Policy{
+		{Subject: "user", Action: "read:own", Object: "employee", Fields: []string{"*", "!salary"}, Filters: []string{"*", "!salary"}},
+		{Subject: "user", Action: "read:shared", Object: "employee", Fields: []string{"name"}, Filters: []string{"name"}},
+	}
+	data := map[string]any{"name": "John", "email": "john@example.com", "salary": 100}
+	cKey := CacheKey{Subject: "user", Action: "read", Object: "employee"}
+
+	expected := map[Merge]map[string]any{
+		MergeUnion:           {"name": "John", "email": "john@example.com"},
+		MergeIntersection:    {"name": "John"},
+		MergeMostRestrictive: {"name": "John"},
+	}
+
+	for mode, want := range expected {
+		t.Run(mode.String(), func(t *testing.T) {
+			g, err := NewWithOptions(policies, Options{Merge: mode})
+			require.NoError(t, err)
+
+			got, err := g.FieldByCKey(data, cKey)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			got, err = g.FilterByCKey(data, cKey)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestGrant_GetWildcards(t *testing.T) {
+	star := policy.Policy{Subject: "admin", Action: "*", Object: "*"}
+	glob := policy.Policy{Subject: "admin", Action: "read", Object: "reports/*"}
+	g, err := New([]policy.Policy{star, glob}, false)
+	require.NoError(t, err)
+
+	pols, ok := g.Get(policy.Policy{Subject: "admin", Action: "read", Object: "reports/q1"})
+	assert.True(t, ok)
+	assert.Equal(t, []policy.Policy{glob}, pols)
+
+	pols, ok = g.Get(policy.Policy{Subject: "admin", Action: "delete", Object: "reports/q1"})
+	assert.True(t, ok)
+	assert.Equal(t, []policy.Policy{star}, pols)
+
+	_, ok = g.Get(policy.Policy{Subject: "user", Action: "read", Object: "reports/q1"})
+	assert.False(t, ok)
+}
+
+func TestGrant_Ordering(t *testing.T) {
+	g, err := New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article:own"},
+		{Subject: "user", Action: "read", Object: "article:shared"},
+		{Subject: "user", Action: "read", Object: "article:all"},
+		{Subject: "user", Action: "update", Object: "article:own"},
+	}, false)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		pols, ok := g.Get(policy.Policy{Subject: "user", Action: "read", Object: "article:.*"})
+		require.True(t, ok)
+		require.Len(t, pols, 3)
+		assert.Equal(t, []string{"article:all", "article:own", "article:shared"}, []string{pols[0].Object, pols[1].Object, pols[2].Object})
+
+		assert.Equal(t, []string{"all", "own", "shared"}, g.Scopes("object"))
+	}
+}
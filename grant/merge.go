@@ -0,0 +1,174 @@
+package grant
+
+import "fmt"
+
+// Merge selects how the views of several matched policies are combined
+// Each policy's Fields or Filters are applied on their own first, so the
+// result never depends on the order of the policies' globs
+type Merge uint8
+
+const (
+	// MergeUnion shows a field if any matched policy shows it
+	MergeUnion Merge = iota
+	// MergeIntersection shows a field only if every matched policy shows it
+	MergeIntersection
+	// MergeMostRestrictive uses the single policy view showing the fewest fields
+	MergeMostRestrictive
+)
+
+func (m Merge) String() string {
+	switch m {
+	case MergeUnion:
+		return "union"
+	case MergeIntersection:
+		return "intersection"
+	case MergeMostRestrictive:
+		return "most-restrictive"
+	}
+	return "unknown"
+}
+
+// merge filters doc once per glob set and combines the views
+func (g *Grant) merge(doc map[string]any, globSets [][]string) (map[string]any, error) {
+	views := make([]map[string]any, 0, len(globSets))
+	for _, globs := range globSets {
+		// Filtering may modify nested maps in place, so every view gets its own copy
+		view, err := filterDocument(clone(doc), globs)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	if len(views) == 0 {
+		return map[string]any{}, nil
+	}
+
+	switch g.mergeMode {
+	case MergeIntersection:
+		result := views[0]
+		for _, view := range views[1:] {
+			result = intersectMap(result, view)
+		}
+		return result, nil
+	case MergeMostRestrictive:
+		result, least := views[0], countFields(views[0])
+		for _, view := range views[1:] {
+			if n := countFields(view); n < least {
+				result, least = view, n
+			}
+		}
+		return result, nil
+	}
+
+	result := map[string]any{}
+	for _, view := range views {
+		result = unionMap(result, view)
+	}
+	return result, nil
+}
+
+// mergeList merges the views of every element of a list, preserving order
+func (g *Grant) mergeList(data any, globSets [][]string) ([]map[string]any, error) {
+	items, err := toList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		doc, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("list element %d is not a document", i)
+		}
+
+		merged, err := g.merge(doc, globSets)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, merged)
+	}
+
+	return result, nil
+}
+
+func unionMap(a, b map[string]any) map[string]any {
+	out := make(map[string]any, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if existing, ok := out[k]; ok {
+			out[k] = unionValue(existing, v)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func unionValue(a, b any) any {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			return unionMap(av, bv)
+		}
+	case []any:
+		if bv, ok := b.([]any); ok && len(av) == len(bv) {
+			out := make([]any, len(av))
+			for i := range av {
+				out[i] = unionValue(av[i], bv[i])
+			}
+			return out
+		}
+	}
+	return a
+}
+
+func intersectMap(a, b map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range a {
+		if other, ok := b[k]; ok {
+			out[k] = intersectValue(v, other)
+		}
+	}
+	return out
+}
+
+func intersectValue(a, b any) any {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			return intersectMap(av, bv)
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			n := min(len(av), len(bv))
+			out := make([]any, n)
+			for i := 0; i < n; i++ {
+				out[i] = intersectValue(av[i], bv[i])
+			}
+			return out
+		}
+	}
+	return a
+}
+
+// countFields counts the leaf fields of a view
+func countFields(v any) int {
+	switch vv := v.(type) {
+	case map[string]any:
+		n := 0
+		for _, sub := range vv {
+			n += countFields(sub)
+		}
+		return n
+	case []any:
+		n := 0
+		for _, sub := range vv {
+			n += countFields(sub)
+		}
+		return n
+	}
+	return 1
+}
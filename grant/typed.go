@@ -0,0 +1,186 @@
+package grant
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// FieldInto applies field filters to a copy of in and returns it with the
+// disallowed fields zeroed, field names follow the json tags of T
+func FieldInto[T any](g *Grant, in T) (T, error) {
+	allowed, err := g.Field(in)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return maskInto(in, allowed), nil
+}
+
+// FilterInto applies data filters to a copy of in and returns it with the
+// disallowed fields zeroed, field names follow the json tags of T
+func FilterInto[T any](g *Grant, in T) (T, error) {
+	allowed, err := g.Filter(in)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return maskInto(in, allowed), nil
+}
+
+func maskInto[T any](in T, allowed map[string]any) T {
+	v := reflect.ValueOf(&in).Elem()
+	return mask(v, allowed).Interface().(T)
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// marshals reports whether values of t write their own JSON form, which
+// does not follow their fields, e.g. time.Time
+func marshals(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	for _, m := range []reflect.Type{jsonMarshaler, textMarshaler} {
+		if t.Implements(m) || reflect.PointerTo(t).Implements(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns a copy of v keeping only the parts present in allowed,
+// the filtered JSON form of v. The original value is never modified
+// Types with their own JSON form are kept whole, their path being allowed
+func mask(v reflect.Value, allowed any) reflect.Value {
+	if marshals(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		elem := mask(v.Elem(), allowed)
+		p := reflect.New(elem.Type())
+		p.Elem().Set(elem)
+		return p
+
+	case reflect.Struct:
+		m, _ := allowed.(map[string]any)
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		maskStruct(out, m)
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		items, _ := allowed.([]any)
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			var item any
+			if i < len(items) {
+				item = items[i]
+			}
+			out.Index(i).Set(mask(v.Index(i), item))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		m, _ := allowed.(map[string]any)
+		out := reflect.MakeMapWithSize(v.Type(), len(m))
+		iter := v.MapRange()
+		for iter.Next() {
+			sub, ok := m[iter.Key().String()]
+			if !ok {
+				continue
+			}
+			out.SetMapIndex(iter.Key(), mask(iter.Value(), sub))
+		}
+		return out
+
+	case reflect.Interface:
+		// The dynamic value is masked, a map[string]any payload like a map field
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(mask(v.Elem(), allowed))
+		return out
+	}
+
+	// Scalars are kept as a whole
+	return v
+}
+
+// maskStruct zeroes the exported fields of v whose JSON names are not in allowed
+func maskStruct(v reflect.Value, allowed map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		field := v.Field(i)
+		name, tagged := jsonName(sf)
+		if name == "-" {
+			if field.CanSet() {
+				field.SetZero()
+			}
+			continue
+		}
+
+		// Untagged embedded structs are flattened into the parent in JSON
+		if sf.Anonymous && !tagged {
+			switch {
+			case field.Kind() == reflect.Struct:
+				maskStruct(field, allowed)
+				continue
+			case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct:
+				if !field.IsNil() && field.CanSet() {
+					elem := reflect.New(field.Type().Elem())
+					elem.Elem().Set(field.Elem())
+					maskStruct(elem.Elem(), allowed)
+					field.Set(elem)
+				}
+				continue
+			}
+		}
+
+		if !field.CanSet() {
+			continue
+		}
+
+		sub, ok := allowed[name]
+		if !ok {
+			field.SetZero()
+			continue
+		}
+		field.Set(mask(field, sub))
+	}
+}
+
+// jsonName returns the JSON key of a struct field and whether it was set by a tag
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup("json")
+	if !ok {
+		return sf.Name, false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return sf.Name, false
+	}
+	return name, true
+}
@@ -0,0 +1,130 @@
+package grant
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ForbiddenFieldsError lists the field paths a write attempted without permission
+// Nested paths use dot notation and list elements their index: "comments[0].author"
+type ForbiddenFieldsError struct {
+	Paths []string
+}
+
+func (e *ForbiddenFieldsError) Error() string {
+	return fmt.Sprintf("forbidden fields: %s", strings.Join(e.Paths, ", "))
+}
+
+// ValidateFields rejects data containing fields the grant does not allow to modify
+// It returns a *ForbiddenFieldsError listing every forbidden path, where Field
+// would silently drop them
+func (g *Grant) ValidateFields(data any) error {
+	doc, err := toDocument(data)
+	if err != nil {
+		return err
+	}
+
+	// Filtering may modify nested maps in place, so work on a copy
+	allowed, err := g.Field(clone(doc))
+	if err != nil {
+		return err
+	}
+
+	return Forbidden(doc, allowed)
+}
+
+// Forbidden compares data with its filtered form and returns a
+// *ForbiddenFieldsError for the paths that were removed, or nil
+func Forbidden(data any, allowed map[string]any) error {
+	doc, err := toDocument(data)
+	if err != nil {
+		return err
+	}
+
+	paths := forbiddenPaths(doc, allowed, "")
+	if len(paths) == 0 {
+		return nil
+	}
+
+	sort.Strings(paths)
+	return &ForbiddenFieldsError{Paths: paths}
+}
+
+func forbiddenPaths(doc, allowed map[string]any, prefix string) []string {
+	paths := []string{}
+	for k, v := range doc {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		a, ok := allowed[k]
+		if !ok {
+			paths = append(paths, path)
+			continue
+		}
+		paths = append(paths, forbiddenValue(v, a, path)...)
+	}
+	return paths
+}
+
+func forbiddenValue(v, allowed any, path string) []string {
+	switch vv := v.(type) {
+	case map[string]any:
+		if a, ok := allowed.(map[string]any); ok {
+			return forbiddenPaths(vv, a, path)
+		}
+	case []any:
+		a, ok := allowed.([]any)
+		if !ok {
+			return nil
+		}
+		paths := []string{}
+		for i, item := range vv {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(a) {
+				paths = append(paths, itemPath)
+				continue
+			}
+			paths = append(paths, forbiddenValue(item, a[i], itemPath)...)
+		}
+		return paths
+	case []map[string]any:
+		items := make([]any, len(vv))
+		for i, m := range vv {
+			items[i] = m
+		}
+		return forbiddenValue(items, allowed, path)
+	}
+	return nil
+}
+
+// clone deep copies the maps and lists of a document
+func clone(doc map[string]any) map[string]any {
+	out := make(map[string]any, len(doc))
+	for k, v := range doc {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		return clone(vv)
+	case []any:
+		out := make([]any, len(vv))
+		for i, item := range vv {
+			out[i] = cloneValue(item)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(vv))
+		for i, item := range vv {
+			out[i] = clone(item)
+		}
+		return out
+	}
+	return v
+}
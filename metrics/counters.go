@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// DecisionKey identifies a decision counter
+type DecisionKey struct {
+	Action  string
+	Object  string
+	Granted bool
+}
+
+// CheckKey identifies a check error counter
+type CheckKey struct {
+	Action string
+	Object string
+}
+
+// Stat counts observations and sums their latency
+type Stat struct {
+	Count uint64
+	Total time.Duration
+	Max   time.Duration
+}
+
+func (s Stat) observe(latency time.Duration) Stat {
+	s.Count++
+	s.Total += latency
+	if latency > s.Max {
+		s.Max = latency
+	}
+	return s
+}
+
+// Counters is an in-memory Recorder, useful for tests or exposing through expvar
+type Counters struct {
+	mu        sync.Mutex
+	decisions map[DecisionKey]Stat
+	checkErrs map[CheckKey]uint64
+	calls     map[string]Stat
+	errors    map[string]uint64
+	policies  int
+}
+
+// NewCounters creates a new in-memory recorder
+func NewCounters() *Counters {
+	return &Counters{
+		decisions: make(map[DecisionKey]Stat),
+		checkErrs: make(map[CheckKey]uint64),
+		calls:     make(map[string]Stat),
+		errors:    make(map[string]uint64),
+	}
+}
+
+func (c *Counters) Decision(action, object string, granted bool, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := DecisionKey{Action: action, Object: object, Granted: granted}
+	c.decisions[key] = c.decisions[key].observe(latency)
+}
+
+func (c *Counters) CheckError(action, object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkErrs[CheckKey{Action: action, Object: object}]++
+}
+
+func (c *Counters) DriverCall(op string, latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls[op] = c.calls[op].observe(latency)
+	if err != nil {
+		c.errors[op]++
+	}
+}
+
+func (c *Counters) PolicyCount(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.policies = n
+}
+
+// Decisions returns a copy of the decision counters
+func (c *Counters) Decisions() map[DecisionKey]Stat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[DecisionKey]Stat, len(c.decisions))
+	for k, v := range c.decisions {
+		out[k] = v
+	}
+	return out
+}
+
+// CheckErrors returns a copy of the check error counters
+func (c *Counters) CheckErrors() map[CheckKey]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[CheckKey]uint64, len(c.checkErrs))
+	for k, v := range c.checkErrs {
+		out[k] = v
+	}
+	return out
+}
+
+// DriverCalls returns a copy of the driver call counters by operation
+func (c *Counters) DriverCalls() map[string]Stat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[string]Stat, len(c.calls))
+	for k, v := range c.calls {
+		out[k] = v
+	}
+	return out
+}
+
+// DriverErrors returns a copy of the driver error counters by operation
+func (c *Counters) DriverErrors() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[string]uint64, len(c.errors))
+	for k, v := range c.errors {
+		out[k] = v
+	}
+	return out
+}
+
+// Policies returns the last reported policy count
+func (c *Counters) Policies() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.policies
+}
@@ -0,0 +1,246 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/driver"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Driver operation names passed to Recorder.DriverCall
+const (
+	OpSet         = "set"
+	OpGet         = "get"
+	OpFind        = "find"
+	OpDelete      = "delete"
+	OpExists      = "exists"
+	OpClear       = "clear"
+	OpList        = "list"
+	OpListTenant  = "list_tenant"
+	OpClearTenant = "clear_tenant"
+	OpRevisions   = "revisions"
+	OpSnapshot    = "snapshot"
+	OpFindAt      = "find_at"
+	OpRollback    = "rollback"
+
+	OpListPage       = "list_page"
+	OpListTenantPage = "list_tenant_page"
+	OpFindPage       = "find_page"
+)
+
+// Driver instruments a driver.Driver
+type Driver struct {
+	next driver.Driver
+	rec  Recorder
+
+	// mu serializes mutations to keep the policy count accurate
+	mu    sync.Mutex
+	count int
+}
+
+// WrapDriver instruments a driver, reporting every call and the policy count to r
+// If d implements driver.Versioned the returned driver does too
+func WrapDriver(d driver.Driver, r Recorder) driver.Driver {
+	w := &Driver{next: d, rec: r, count: len(d.List())}
+	r.PolicyCount(w.count)
+
+	if v, ok := d.(driver.Versioned); ok {
+		return &VersionedDriver{Driver: w, versioned: v}
+	}
+	return w
+}
+
+func (d *Driver) observe(op string, start time.Time, err error) {
+	d.rec.DriverCall(op, time.Since(start), err)
+}
+
+func (d *Driver) Set(p policy.Policy) error {
+	return d.mutateSet(p, func() error { return d.next.Set(p) })
+}
+
+func (d *Driver) Get(key string) (policy.Policy, bool) {
+	start := time.Now()
+	p, ok := d.next.Get(key)
+	d.observe(OpGet, start, nil)
+	return p, ok
+}
+
+func (d *Driver) Find(patternPolicy policy.Policy) ([]policy.Policy, error) {
+	start := time.Now()
+	policies, err := d.next.Find(patternPolicy)
+	d.observe(OpFind, start, err)
+	return policies, err
+}
+
+// FindContext passes the context on when the wrapped driver implements driver.ContextFinder
+func (d *Driver) FindContext(ctx context.Context, patternPolicy policy.Policy) ([]policy.Policy, error) {
+	f, ok := d.next.(driver.ContextFinder)
+	if !ok {
+		return d.Find(patternPolicy)
+	}
+
+	start := time.Now()
+	policies, err := f.FindContext(ctx, patternPolicy)
+	d.observe(OpFind, start, err)
+	return policies, err
+}
+
+func (d *Driver) Delete(key string) error {
+	return d.mutateDelete(key, func() error { return d.next.Delete(key) })
+}
+
+func (d *Driver) Exists(key string) bool {
+	start := time.Now()
+	ok := d.next.Exists(key)
+	d.observe(OpExists, start, nil)
+	return ok
+}
+
+func (d *Driver) Clear() error {
+	return d.mutateAll(OpClear, d.next.Clear)
+}
+
+func (d *Driver) List() []string {
+	start := time.Now()
+	keys := d.next.List()
+	d.observe(OpList, start, nil)
+	return keys
+}
+
+// ListTenant lists through the wrapped driver, see driver.ListTenant
+func (d *Driver) ListTenant(tenant string) []string {
+	start := time.Now()
+	keys := driver.ListTenant(d.next, tenant)
+	d.observe(OpListTenant, start, nil)
+	return keys
+}
+
+// ListPage pages through the wrapped driver, see driver.ListPage
+func (d *Driver) ListPage(req driver.PageRequest) (driver.Page[string], error) {
+	start := time.Now()
+	page, err := driver.ListPage(d.next, req)
+	d.observe(OpListPage, start, err)
+	return page, err
+}
+
+// ListTenantPage pages through the wrapped driver, see driver.ListTenantPage
+func (d *Driver) ListTenantPage(tenant string, req driver.PageRequest) (driver.Page[string], error) {
+	start := time.Now()
+	page, err := driver.ListTenantPage(d.next, tenant, req)
+	d.observe(OpListTenantPage, start, err)
+	return page, err
+}
+
+// FindPage pages through the wrapped driver, see driver.FindPage
+func (d *Driver) FindPage(patternPolicy policy.Policy, req driver.PageRequest) (driver.Page[policy.Policy], error) {
+	start := time.Now()
+	page, err := driver.FindPage(d.next, patternPolicy, req)
+	d.observe(OpFindPage, start, err)
+	return page, err
+}
+
+// ClearTenant clears through the wrapped driver, see driver.ClearTenant
+func (d *Driver) ClearTenant(tenant string) error {
+	return d.mutateAll(OpClearTenant, func() error { return driver.ClearTenant(d.next, tenant) })
+}
+
+// mutateSet runs a set, counting the policy if it is new
+func (d *Driver) mutateSet(p policy.Policy, set func() error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existed := d.next.Exists(p.Key())
+	start := time.Now()
+	err := set()
+	d.observe(OpSet, start, err)
+
+	if err == nil && !existed {
+		d.count++
+		d.rec.PolicyCount(d.count)
+	}
+	return err
+}
+
+// mutateDelete runs a delete, uncounting the policy if it existed
+func (d *Driver) mutateDelete(key string, del func() error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existed := d.next.Exists(key)
+	start := time.Now()
+	err := del()
+	d.observe(OpDelete, start, err)
+
+	if err == nil && existed {
+		d.count--
+		d.rec.PolicyCount(d.count)
+	}
+	return err
+}
+
+// mutateAll runs a mutation affecting many policies and recounts them
+func (d *Driver) mutateAll(op string, mutate func() error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	start := time.Now()
+	err := mutate()
+	d.observe(op, start, err)
+
+	d.count = len(d.next.List())
+	d.rec.PolicyCount(d.count)
+	return err
+}
+
+// VersionedDriver instruments a driver.Versioned
+type VersionedDriver struct {
+	*Driver
+	versioned driver.Versioned
+}
+
+func (d *VersionedDriver) SetAs(author string, p policy.Policy) error {
+	return d.mutateSet(p, func() error { return d.versioned.SetAs(author, p) })
+}
+
+func (d *VersionedDriver) DeleteAs(author string, key string) error {
+	return d.mutateDelete(key, func() error { return d.versioned.DeleteAs(author, key) })
+}
+
+func (d *VersionedDriver) ClearAs(author string) error {
+	return d.mutateAll(OpClear, func() error { return d.versioned.ClearAs(author) })
+}
+
+func (d *VersionedDriver) ClearTenantAs(author string, tenant string) error {
+	return d.mutateAll(OpClearTenant, func() error { return d.versioned.ClearTenantAs(author, tenant) })
+}
+
+func (d *VersionedDriver) Revisions() []driver.Revision {
+	start := time.Now()
+	revs := d.versioned.Revisions()
+	d.observe(OpRevisions, start, nil)
+	return revs
+}
+
+func (d *VersionedDriver) Snapshot(rev int64) ([]policy.Policy, error) {
+	start := time.Now()
+	policies, err := d.versioned.Snapshot(rev)
+	d.observe(OpSnapshot, start, err)
+	return policies, err
+}
+
+func (d *VersionedDriver) FindAt(rev int64, patternPolicy policy.Policy) ([]policy.Policy, error) {
+	start := time.Now()
+	policies, err := d.versioned.FindAt(rev, patternPolicy)
+	d.observe(OpFindAt, start, err)
+	return policies, err
+}
+
+func (d *VersionedDriver) Rollback(author string, rev int64) error {
+	return d.mutateAll(OpRollback, func() error { return d.versioned.Rollback(author, rev) })
+}
+
+func (d *VersionedDriver) RollbackTenant(author string, tenant string, rev int64) error {
+	return d.mutateAll(OpRollback, func() error { return d.versioned.RollbackTenant(author, tenant, rev) })
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/audit"
+)
+
+// Recorder receives measurements from the instrumented components
+// Implement it on top of Prometheus, OpenTelemetry or any other metrics library
+type Recorder interface {
+	// Decision counts a check outcome and observes its latency
+	Decision(action, object string, granted bool, latency time.Duration)
+
+	// CheckError counts a check that failed, e.g. an invalid request or a driver error
+	CheckError(action, object string)
+
+	// DriverCall counts a driver operation and observes its latency, err is nil on success
+	DriverCall(op string, latency time.Duration, err error)
+
+	// PolicyCount reports the number of stored policies
+	PolicyCount(n int)
+}
+
+// Logger returns a decision logger recording every check of an access control
+// Combine it with other decision loggers using audit.Multi
+// Failed checks are counted as check errors rather than decisions
+func Logger(r Recorder) audit.Logger {
+	return audit.LoggerFunc(func(d audit.Decision) {
+		if d.Error != "" {
+			r.CheckError(d.Action, d.Object)
+			return
+		}
+		r.Decision(d.Action, d.Object, d.Granted, d.Latency)
+	})
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentation(t *testing.T) {
+	rec := NewCounters()
+	drv := WrapDriver(memory.NewMemoryDriver(), rec)
+
+	_, ok := drv.(driver.Versioned)
+	assert.True(t, ok, "versioned drivers stay versioned")
+
+	read := policy.Policy{Subject: "user", Action: "read", Object: "article"}
+	write := policy.Policy{Subject: "user", Action: "write", Object: "article"}
+
+	ac, err := acl.New([]policy.Policy{read, write}, acl.Options{Logger: Logger(rec)}, drv)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rec.Policies())
+
+	// Updating an existing policy does not change the count
+	require.NoError(t, ac.Add(read))
+	assert.Equal(t, 2, rec.Policies())
+
+	for i := 0; i < 3; i++ {
+		_, err := ac.Check([]string{"user"}, "read", "article")
+		require.NoError(t, err)
+	}
+	_, err = ac.Check([]string{"guest"}, "read", "article")
+	require.NoError(t, err)
+
+	decisions := rec.Decisions()
+	assert.Equal(t, uint64(3), decisions[DecisionKey{Action: "read", Object: "article", Granted: true}].Count)
+	assert.Equal(t, uint64(1), decisions[DecisionKey{Action: "read", Object: "article", Granted: false}].Count)
+
+	// Failed checks are counted apart from the decisions
+	_, err = ac.Check(nil, "read", "article")
+	require.Error(t, err)
+	_, err = ac.Check([]string{"user"}, "read", "")
+	require.Error(t, err)
+	assert.Equal(t, map[CheckKey]uint64{{Action: "read", Object: "article"}: 1, {Action: "read"}: 1}, rec.CheckErrors())
+	assert.Len(t, rec.Decisions(), 2)
+
+	calls := rec.DriverCalls()
+	assert.Equal(t, uint64(3), calls[OpSet].Count)
+	assert.Equal(t, uint64(4), calls[OpFind].Count)
+
+	require.NoError(t, ac.Remove(write))
+	assert.Equal(t, 1, rec.Policies())
+	require.NoError(t, ac.Remove(write))
+	assert.Equal(t, 1, rec.Policies())
+
+	require.NoError(t, ac.As("alice").Clear())
+	assert.Equal(t, 0, rec.Policies())
+
+	// The second removal deleted nothing and recorded no revision
+	revs, err := ac.Revisions()
+	require.NoError(t, err)
+	assert.Len(t, revs, 5)
+
+	// Invalid policies are counted as driver errors
+	assert.Error(t, ac.Add(policy.Policy{Subject: "user"}))
+	assert.Equal(t, uint64(1), rec.DriverErrors()[OpSet])
+	assert.Equal(t, 0, rec.Policies())
+}
+
+func TestWrapDriver_Plain(t *testing.T) {
+	var d driver.Driver = &plainDriver{memory.NewMemoryDriver()}
+	wrapped := WrapDriver(d, NewCounters())
+
+	_, ok := wrapped.(driver.Versioned)
+	assert.False(t, ok, "plain drivers must not claim versioning")
+}
+
+func TestWrapDriver_Paginator(t *testing.T) {
+	for name, d := range map[string]driver.Driver{
+		"versioned": memory.NewMemoryDriver(),
+		"plain":     &reversedDriver{plainDriver{memory.NewMemoryDriver()}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := NewCounters()
+			drv := WrapDriver(d, rec)
+			_, ok := drv.(driver.Paginator)
+			require.True(t, ok, "wrapped drivers page through their policies")
+
+			ac, err := acl.New([]policy.Policy{
+				{Subject: "user", Action: "read", Object: "c"},
+				{Subject: "user", Action: "read", Object: "a"},
+				{Subject: "user", Action: "read", Object: "b"},
+			}, acl.Options{}, drv)
+			require.NoError(t, err)
+
+			first, err := ac.ListPage(driver.PageRequest{Limit: 2})
+			require.NoError(t, err)
+			require.Len(t, first.Items, 2)
+			assert.Equal(t, "a", first.Items[0].Object)
+			assert.Equal(t, "b", first.Items[1].Object)
+
+			second, err := ac.ListPage(driver.PageRequest{Cursor: first.Next, Limit: 2})
+			require.NoError(t, err)
+			require.Len(t, second.Items, 1)
+			assert.Equal(t, "c", second.Items[0].Object)
+			assert.Empty(t, second.Next)
+			assert.Equal(t, uint64(2), rec.DriverCalls()[OpListPage].Count)
+
+			page, err := drv.(driver.Paginator).FindPage(policy.Policy{Subject: "user", Action: "read", Object: ".*"}, driver.PageRequest{Limit: 1})
+			require.NoError(t, err)
+			require.Len(t, page.Items, 1)
+			assert.Equal(t, "a", page.Items[0].Object)
+			assert.Equal(t, uint64(1), rec.DriverCalls()[OpFindPage].Count)
+		})
+	}
+}
+
+// plainDriver hides the versioning methods of the memory driver
+type plainDriver struct {
+	driver.Driver
+}
+
+// reversedDriver lists keys in reverse order, paging must not rely on the driver's order
+type reversedDriver struct {
+	plainDriver
+}
+
+func (d *reversedDriver) List() []string {
+	keys := d.plainDriver.List()
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package obligation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Handler fulfills obligations of one type
+type Handler interface {
+	Fulfill(ctx context.Context, o policy.Obligation) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, o policy.Obligation) error
+
+func (f HandlerFunc) Fulfill(ctx context.Context, o policy.Obligation) error {
+	return f(ctx, o)
+}
+
+// UnhandledError lists obligation types without a registered handler
+type UnhandledError struct {
+	Types []string
+}
+
+func (e *UnhandledError) Error() string {
+	return "no handler for obligations: " + strings.Join(e.Types, ", ")
+}
+
+// Registry maps obligation types to their handlers
+// Pass it as acl.Options.Obligations to make checks fail on unhandled obligations
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewRegistry creates a new empty registry
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register sets the handler of an obligation type, replacing any previous one
+func (r *Registry) Register(typ string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[typ] = h
+}
+
+// Handles reports whether a handler is registered for the obligation type
+func (r *Registry) Handles(typ string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.handlers[typ]
+	return ok
+}
+
+// Unhandled returns an *UnhandledError if an obligation, advice excluded, has no handler
+func (r *Registry) Unhandled(obligations []policy.Obligation) error {
+	seen := map[string]bool{}
+	var missing []string
+	for _, o := range obligations {
+		if o.Advice || seen[o.Type] || r.Handles(o.Type) {
+			continue
+		}
+		seen[o.Type] = true
+		missing = append(missing, o.Type)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	return &UnhandledError{Types: missing}
+}
+
+// Enforce fulfills the obligations and advice of a permission, in order
+// Obligations must all be handled and succeed, advice without a handler is
+// skipped and its failures are ignored
+func (r *Registry) Enforce(ctx context.Context, perm *permission.Permission) error {
+	obligations := perm.Obligations()
+	if err := r.Unhandled(obligations); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, o := range obligations {
+		r.mu.RLock()
+		h, ok := r.handlers[o.Type]
+		r.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		if err := h.Fulfill(ctx, o); err != nil && !o.Advice {
+			errs = append(errs, fmt.Errorf("obligation %s failed: %w", o.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package obligation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/audit"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var policies = []policy.Policy{
+	{
+		Subject: "user",
+		Action:  "download",
+		Object:  "report",
+		Obligations: []policy.Obligation{
+			{Type: "watermark", Params: map[string]any{"text": "confidential"}},
+			{Type: "notify", Advice: true},
+		},
+	},
+	{
+		Subject: "auditor",
+		Action:  "download",
+		Object:  "report",
+		Obligations: []policy.Obligation{
+			{Type: "watermark", Params: map[string]any{"text": "confidential"}},
+			{Type: "compliance-log"},
+		},
+	},
+}
+
+func TestEnforce(t *testing.T) {
+	ac, err := acl.New(policies, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	perm, err := ac.Check([]string{"user", "auditor"}, "download", "report")
+	require.NoError(t, err)
+	assert.Len(t, perm.Obligations(), 3, "duplicates are dropped")
+
+	var fulfilled []string
+	record := HandlerFunc(func(ctx context.Context, o policy.Obligation) error {
+		fulfilled = append(fulfilled, o.Type)
+		return nil
+	})
+
+	reg := NewRegistry()
+	reg.Register("watermark", record)
+
+	var unhandled *UnhandledError
+	require.ErrorAs(t, reg.Enforce(context.Background(), perm), &unhandled)
+	assert.Equal(t, []string{"compliance-log"}, unhandled.Types)
+	assert.Empty(t, fulfilled)
+
+	reg.Register("compliance-log", record)
+	require.NoError(t, reg.Enforce(context.Background(), perm))
+	assert.Equal(t, []string{"watermark", "compliance-log"}, fulfilled, "advice without a handler is skipped")
+
+	// Failing advice is ignored, failing obligations are not
+	failing := HandlerFunc(func(ctx context.Context, o policy.Obligation) error { return errors.New("unavailable") })
+	reg.Register("notify", failing)
+	require.NoError(t, reg.Enforce(context.Background(), perm))
+	reg.Register("watermark", failing)
+	assert.ErrorContains(t, reg.Enforce(context.Background(), perm), "obligation watermark failed: unavailable")
+}
+
+func TestCheck_RequireHandlers(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("watermark", HandlerFunc(func(ctx context.Context, o policy.Obligation) error { return nil }))
+
+	var decisions []audit.Decision
+	logger := audit.LoggerFunc(func(d audit.Decision) { decisions = append(decisions, d) })
+
+	ac, err := acl.New(policies, acl.Options{Obligations: reg, Logger: logger}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	perm, err := ac.Check([]string{"user"}, "download", "report")
+	require.NoError(t, err, "advice does not need a handler")
+	assert.True(t, perm.Granted())
+
+	_, err = ac.Check([]string{"auditor"}, "download", "report")
+	var unhandled *UnhandledError
+	require.ErrorAs(t, err, &unhandled)
+	assert.Equal(t, []string{"compliance-log"}, unhandled.Types)
+
+	// The failed check is logged with its error and the policies it matched
+	require.Len(t, decisions, 2)
+	assert.False(t, decisions[1].Granted)
+	assert.Contains(t, decisions[1].Error, "compliance-log")
+	assert.Len(t, decisions[1].Policies, 1)
+
+	perm, err = ac.Check([]string{"guest"}, "download", "report")
+	require.NoError(t, err, "denied checks carry no obligations")
+	assert.Nil(t, perm.Obligations())
+}
@@ -0,0 +1,198 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Key is a verification key of a KeySet
+type Key struct {
+	ID  string // "kid", matched against the token header when both are set
+	Alg string // "alg", restricts the key to one algorithm when set
+
+	// Public is a *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey,
+	// or a []byte secret for HMAC
+	Public any
+}
+
+// KeySet holds the keys tokens are verified with
+type KeySet struct {
+	keys []Key
+}
+
+// NewKeySet creates a key set from static keys
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	ks := &KeySet{}
+	for _, k := range keys {
+		if err := ks.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// Add adds a key to the set
+func (ks *KeySet) Add(k Key) error {
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	case ed25519.PublicKey:
+		if len(pub) != ed25519.PublicKeySize {
+			return fmt.Errorf("key %q: invalid ed25519 key size", k.ID)
+		}
+	case []byte:
+		if len(pub) == 0 {
+			return fmt.Errorf("key %q: hmac secret cannot be empty", k.ID)
+		}
+	default:
+		return fmt.Errorf("key %q: unsupported key type %T", k.ID, k.Public)
+	}
+	ks.keys = append(ks.keys, k)
+	return nil
+}
+
+// Keys returns the keys of the set
+func (ks *KeySet) Keys() []Key {
+	return ks.keys
+}
+
+// candidates returns the keys able to verify a token with the given kid and alg
+func (ks *KeySet) candidates(kid, alg string) []Key {
+	var out []Key
+	for _, k := range ks.keys {
+		if kid != "" && k.ID != "" && k.ID != kid {
+			continue
+		}
+		if k.Alg != "" && k.Alg != alg {
+			continue
+		}
+		if !algorithms[alg].fits(k.Public) {
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// jwk is a JSON Web Key, RFC 7517
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS reads a JSON Web Key Set, {"keys": [...]}
+// Keys with "use" other than "sig" are ignored
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	ks := &KeySet{}
+	for i, j := range doc.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		pub, err := j.public()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %d (%s): %w", i, j.Kid, err)
+		}
+		if err := ks.Add(Key{ID: j.Kid, Alg: j.Alg, Public: pub}); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// LoadJWKS reads a JSON Web Key Set file
+func LoadJWKS(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+func (j jwk) public() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", j.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(j.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
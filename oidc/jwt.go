@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+// Verification errors, test with errors.Is
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrClaims    = errors.New("invalid token claims")
+)
+
+// algorithm verifies the signatures of one JWS algorithm
+type algorithm struct {
+	fits   func(key any) bool
+	verify func(key any, data, sig []byte) bool
+}
+
+// algorithms are the supported JWS algorithms, "none" is not one of them
+var algorithms = map[string]algorithm{
+	"RS256": rsaPKCS1(crypto.SHA256),
+	"RS384": rsaPKCS1(crypto.SHA384),
+	"RS512": rsaPKCS1(crypto.SHA512),
+	"PS256": rsaPSS(crypto.SHA256),
+	"PS384": rsaPSS(crypto.SHA384),
+	"PS512": rsaPSS(crypto.SHA512),
+	"ES256": ecdsaAlg(crypto.SHA256, 256),
+	"ES384": ecdsaAlg(crypto.SHA384, 384),
+	"ES512": ecdsaAlg(crypto.SHA512, 521),
+	"EdDSA": {
+		fits: func(key any) bool { _, ok := key.(ed25519.PublicKey); return ok },
+		verify: func(key any, data, sig []byte) bool {
+			return ed25519.Verify(key.(ed25519.PublicKey), data, sig)
+		},
+	},
+	"HS256": hmacAlg(sha256.New),
+	"HS384": hmacAlg(sha512.New384),
+	"HS512": hmacAlg(sha512.New),
+}
+
+func (a algorithm) valid() bool {
+	return a.fits != nil
+}
+
+func digest(h crypto.Hash, data []byte) []byte {
+	d := h.New()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func rsaPKCS1(h crypto.Hash) algorithm {
+	return algorithm{
+		fits: func(key any) bool { _, ok := key.(*rsa.PublicKey); return ok },
+		verify: func(key any, data, sig []byte) bool {
+			return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), h, digest(h, data), sig) == nil
+		},
+	}
+}
+
+func rsaPSS(h crypto.Hash) algorithm {
+	return algorithm{
+		fits: func(key any) bool { _, ok := key.(*rsa.PublicKey); return ok },
+		verify: func(key any, data, sig []byte) bool {
+			return rsa.VerifyPSS(key.(*rsa.PublicKey), h, digest(h, data), sig, nil) == nil
+		},
+	}
+}
+
+// ecdsaAlg verifies the raw r || s signatures of JWS
+func ecdsaAlg(h crypto.Hash, bits int) algorithm {
+	return algorithm{
+		fits: func(key any) bool {
+			k, ok := key.(*ecdsa.PublicKey)
+			return ok && k.Curve.Params().BitSize == bits
+		},
+		verify: func(key any, data, sig []byte) bool {
+			size := (bits + 7) / 8
+			if len(sig) != 2*size {
+				return false
+			}
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			return ecdsa.Verify(key.(*ecdsa.PublicKey), digest(h, data), r, s)
+		},
+	}
+}
+
+func hmacAlg(h func() hash.Hash) algorithm {
+	return algorithm{
+		fits: func(key any) bool { _, ok := key.([]byte); return ok },
+		verify: func(key any, data, sig []byte) bool {
+			mac := hmac.New(h, key.([]byte))
+			mac.Write(data)
+			return hmac.Equal(mac.Sum(nil), sig)
+		},
+	}
+}
+
+// verify checks the signature of a compact JWS and returns its claims
+func verify(token string, keys *KeySet) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJSON(parts[0], &header); err != nil {
+		return nil, err
+	}
+	alg := algorithms[header.Alg]
+	if !alg.valid() {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrSignature, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range keys.candidates(header.Kid, header.Alg) {
+		if alg.verify(k.Public, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrSignature
+	}
+
+	var claims map[string]any
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeJSON(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
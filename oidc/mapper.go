@@ -0,0 +1,214 @@
+package oidc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// subjectValue is a claim value usable as a subject, "editor" or "articles:read"
+// "@" is excluded, it separates the tenant of policy keys: "acme@editor" would
+// name acme's editor policies from a global check
+var subjectValue = regexp.MustCompile(`^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$`)
+
+// Source extracts subjects from a claim
+type Source struct {
+	// Claim is the dot path of the claim: "roles", "realm_access.roles"
+	// String claims are split on spaces, like the OAuth "scope" claim
+	Claim string
+
+	// Prefix is prepended to every value: "group_" maps "admins" to "group_admins"
+	Prefix string
+
+	// Scope is appended to every value: "own" maps "editor" to "editor:own"
+	Scope string
+
+	// ScopeClaim appends the value of a claim instead: "tenant" maps "editor" to "editor:acme"
+	// No subject is extracted when the claim is missing
+	ScopeClaim string
+}
+
+// Mapping configures how tokens map to subjects and attributes
+type Mapping struct {
+	Subjects []Source
+
+	// Default subjects are added to every identity, e.g. "authenticated"
+	Default []string
+
+	// Attributes maps attribute names to claim paths, e.g. {"id": "sub", "org": "org.id"}
+	// nil exposes every claim under its own name
+	Attributes map[string]string
+
+	// Issuer and Audience are required to match the "iss" and "aud" claims when set
+	Issuer   string
+	Audience string
+
+	// Leeway tolerates clock skew when checking "exp" and "nbf"
+	Leeway time.Duration
+}
+
+// Identity is the result of mapping a token
+type Identity struct {
+	ID         string         // The "sub" claim
+	Subjects   []string       // For acl.Request.Subjects
+	Attributes map[string]any // For acl.Request.Attributes and condition "$name" references
+	Claims     map[string]any // Every claim of the token
+}
+
+// Mapper verifies tokens and maps their claims to identities
+type Mapper struct {
+	keys    *KeySet
+	mapping Mapping
+	now     func() time.Time
+}
+
+// NewMapper creates a mapper verifying tokens against keys
+func NewMapper(keys *KeySet, m Mapping) (*Mapper, error) {
+	if keys == nil || len(keys.keys) == 0 {
+		return nil, fmt.Errorf("at least one key is required")
+	}
+	for i, s := range m.Subjects {
+		if s.Claim == "" {
+			return nil, fmt.Errorf("subject source %d has no claim", i)
+		}
+		if s.Scope != "" && s.ScopeClaim != "" {
+			return nil, fmt.Errorf("subject source %s cannot have both a scope and a scope claim", s.Claim)
+		}
+	}
+	return &Mapper{keys: keys, mapping: m, now: time.Now}, nil
+}
+
+// Map verifies a compact JWT, with or without a "Bearer " prefix, and maps its claims
+func (m *Mapper) Map(token string) (*Identity, error) {
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+	claims, err := verify(token, m.keys)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validate(claims); err != nil {
+		return nil, err
+	}
+	return m.MapClaims(claims), nil
+}
+
+// MapClaims maps claims that were already verified
+// Values that cannot be subjects, e.g. containing spaces, "*" or ".", are skipped
+func (m *Mapper) MapClaims(claims map[string]any) *Identity {
+	id := &Identity{Claims: claims, Subjects: []string{}}
+	id.ID, _ = claims["sub"].(string)
+
+	add := func(s string) {
+		if !slices.Contains(id.Subjects, s) {
+			id.Subjects = append(id.Subjects, s)
+		}
+	}
+
+	for _, src := range m.mapping.Subjects {
+		scope := src.Scope
+		if src.ScopeClaim != "" {
+			v, _ := policy.Lookup(claims, src.ScopeClaim)
+			s, ok := v.(string)
+			if !ok || !subjectValue.MatchString(s) {
+				continue
+			}
+			scope = s
+		}
+
+		for _, v := range values(claims, src.Claim) {
+			s := src.Prefix + v
+			if scope != "" {
+				s += ":" + scope
+			}
+			if subjectValue.MatchString(s) {
+				add(s)
+			}
+		}
+	}
+	for _, s := range m.mapping.Default {
+		add(s)
+	}
+
+	if m.mapping.Attributes == nil {
+		id.Attributes = make(map[string]any, len(claims))
+		for k, v := range claims {
+			id.Attributes[k] = v
+		}
+		return id
+	}
+	id.Attributes = make(map[string]any, len(m.mapping.Attributes))
+	for name, path := range m.mapping.Attributes {
+		if v, ok := policy.Lookup(claims, path); ok {
+			id.Attributes[name] = v
+		}
+	}
+	return id
+}
+
+// values returns the string values of a claim, splitting string claims on spaces
+func values(claims map[string]any, path string) []string {
+	v, ok := policy.Lookup(claims, path)
+	if !ok {
+		return nil
+	}
+
+	if s, ok := v.(string); ok {
+		return strings.Fields(s)
+	}
+	return stringList(v)
+}
+
+// validate checks the registered claims: exp, nbf, iss and aud
+func (m *Mapper) validate(claims map[string]any) error {
+	now := m.now()
+	leeway := m.mapping.Leeway
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: exp is required", ErrClaims)
+	}
+	if !now.Before(unix(exp).Add(leeway)) {
+		return fmt.Errorf("%w: token expired", ErrClaims)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(leeway).Before(unix(nbf)) {
+		return fmt.Errorf("%w: token not valid yet", ErrClaims)
+	}
+
+	if m.mapping.Issuer != "" && claims["iss"] != m.mapping.Issuer {
+		return fmt.Errorf("%w: issuer %v, expected %s", ErrClaims, claims["iss"], m.mapping.Issuer)
+	}
+	if m.mapping.Audience != "" && !slices.Contains(audience(claims["aud"]), m.mapping.Audience) {
+		return fmt.Errorf("%w: audience %v, expected %s", ErrClaims, claims["aud"], m.mapping.Audience)
+	}
+	return nil
+}
+
+func unix(sec float64) time.Time {
+	return time.Unix(0, int64(sec*float64(time.Second)))
+}
+
+// audience returns the "aud" claim, a string or a list of strings
+func audience(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return stringList(v)
+}
+
+// stringList returns the strings of a JSON array, other elements are skipped
+func stringList(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, x := range list {
+		if s, ok := x.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,233 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var b64 = base64.RawURLEncoding
+
+type keys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+	ed  ed25519.PrivateKey
+}
+
+func newKeys(t *testing.T) (keys, string) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256",
+			"n": b64.EncodeToString(rsaKey.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256",
+			"x": b64.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))), "y": b64.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": b64.EncodeToString(edKey.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+	return keys{rsa: rsaKey, ec: ecKey, ed: edKey}, path
+}
+
+func sign(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	h, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64.EncodeToString(h) + "." + b64.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(signed))
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	return signed + "." + b64.EncodeToString(sig)
+}
+
+func claims(extra map[string]any) map[string]any {
+	c := map[string]any{
+		"sub":    "alice",
+		"iss":    "https://id.example.com",
+		"aud":    []string{"articles-api", "other"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"roles":  []string{"editor", "bad role", "*", "acme@admin"},
+		"groups": []string{"writers"},
+		"scope":  "openid articles:read",
+		"tenant": "acme",
+		"org":    map[string]any{"id": "42"},
+	}
+	for k, v := range extra {
+		c[k] = v
+	}
+	return c
+}
+
+var mapping = Mapping{
+	Subjects: []Source{
+		{Claim: "roles"},
+		{Claim: "groups", Prefix: "group:"},
+		{Claim: "scope"},
+		{Claim: "roles", ScopeClaim: "tenant"},
+	},
+	Default:    []string{"authenticated"},
+	Attributes: map[string]string{"id": "sub", "org": "org.id"},
+	Issuer:     "https://id.example.com",
+	Audience:   "articles-api",
+}
+
+func TestMapper_Map(t *testing.T) {
+	k, path := newKeys(t)
+	ks, err := LoadJWKS(path)
+	require.NoError(t, err)
+	require.Len(t, ks.Keys(), 3) // The encryption key is ignored
+
+	m, err := NewMapper(ks, mapping)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		alg, kid string
+		key      any
+	}{
+		{"RS256", "rsa-1", k.rsa},
+		{"ES256", "ec-1", k.ec},
+		{"EdDSA", "ed-1", k.ed},
+		{"EdDSA", "", k.ed},
+	} {
+		t.Run(tc.alg+" "+tc.kid, func(t *testing.T) {
+			id, err := m.Map("Bearer " + sign(t, tc.alg, tc.kid, tc.key, claims(nil)))
+			require.NoError(t, err)
+
+			assert.Equal(t, "alice", id.ID)
+			assert.Equal(t, []string{"editor", "group:writers", "openid", "articles:read", "editor:acme", "authenticated"}, id.Subjects)
+			assert.Equal(t, map[string]any{"id": "alice", "org": "42"}, id.Attributes)
+		})
+	}
+}
+
+func TestMapper_Rejects(t *testing.T) {
+	k, path := newKeys(t)
+	ks, err := LoadJWKS(path)
+	require.NoError(t, err)
+	m, err := NewMapper(ks, mapping)
+	require.NoError(t, err)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	valid := sign(t, "RS256", "rsa-1", k.rsa, claims(nil))
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"unknown key", sign(t, "RS256", "rsa-1", other, claims(nil)), ErrSignature},
+		{"wrong kid", sign(t, "RS256", "ec-1", k.rsa, claims(nil)), ErrSignature},
+		{"hmac with public key", sign(t, "HS256", "rsa-1", []byte(b64.EncodeToString(k.rsa.N.Bytes())), claims(nil)), ErrSignature},
+		{"none", b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte(`{"sub":"alice"}`)) + ".", ErrSignature},
+		{"tampered", valid[:len(valid)-4] + "AAAA", ErrSignature},
+		{"malformed", "abc.def", ErrMalformed},
+		{"expired", sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"exp": time.Now().Add(-time.Minute).Unix()})), ErrClaims},
+		{"no expiry", sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"exp": nil})), ErrClaims},
+		{"not yet valid", sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()})), ErrClaims},
+		{"issuer", sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"iss": "https://evil.example.com"})), ErrClaims},
+		{"audience", sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"aud": "billing-api"})), ErrClaims},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := m.Map(tc.token)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	// Leeway tolerates a token expired a moment ago
+	lenient := *m
+	lenient.mapping.Leeway = time.Minute
+	_, err = lenient.Map(sign(t, "RS256", "rsa-1", k.rsa, claims(map[string]any{"exp": time.Now().Add(-time.Second).Unix()})))
+	assert.NoError(t, err)
+}
+
+func TestMapper_StaticKeys(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	ks, err := NewKeySet(Key{ID: "shared", Public: secret})
+	require.NoError(t, err)
+
+	m, err := NewMapper(ks, Mapping{Subjects: []Source{{Claim: "realm_access.roles", Scope: "own"}}})
+	require.NoError(t, err)
+
+	id, err := m.Map(sign(t, "HS256", "shared", secret, map[string]any{
+		"sub":          "bob",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{"roles": []string{"user"}},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:own"}, id.Subjects)
+	assert.Equal(t, "bob", id.Attributes["sub"]) // Every claim without Attributes
+
+	_, err = NewKeySet(Key{ID: "bad", Public: "secret"})
+	assert.ErrorContains(t, err, "unsupported key type string")
+	_, err = NewMapper(ks, Mapping{Subjects: []Source{{Claim: "roles", Scope: "own", ScopeClaim: "tenant"}}})
+	assert.Error(t, err)
+}
+
+func TestMapper_Check(t *testing.T) {
+	k, path := newKeys(t)
+	ks, err := LoadJWKS(path)
+	require.NoError(t, err)
+	m, err := NewMapper(ks, mapping)
+	require.NoError(t, err)
+
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "editor", Action: "update", Object: "article", Conditions: []policy.Condition{
+			{Field: "author", Op: policy.OpEq, Value: "$id"},
+		}},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	id, err := m.Map(sign(t, "ES256", "ec-1", k.ec, claims(nil)))
+	require.NoError(t, err)
+
+	perm, err := ac.CheckRequest(acl.Request{Subjects: id.Subjects, Action: "update", Object: "article", Attributes: id.Attributes})
+	require.NoError(t, err)
+	require.True(t, perm.Granted())
+
+	rows, err := perm.Rows([]map[string]any{{"author": "alice"}, {"author": "bob"}}, id.Attributes)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"author": "alice"}}, rows)
+}
@@ -0,0 +1,218 @@
+package opa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+)
+
+// DataPrefix is the path of the OPA data API
+const DataPrefix = "/v1/data"
+
+// MaxBodyBytes is the largest request body the handler reads, larger bodies are rejected
+const MaxBodyBytes = 1 << 20
+
+// Input is the input document of an OPA query, as sent by OPA clients
+type Input struct {
+	Subject  string   `json:"subject"`  // A single subject, merged into Subjects
+	Subjects []string `json:"subjects"` // e.g. the roles of the user
+	Action   string   `json:"action"`
+	Object   string   `json:"object"`
+
+	// Strict overrides the strict mode of the access control
+	Strict *bool `json:"strict"`
+
+	// Tenant scopes the check with acl.AccessControl.Tenant
+	Tenant string `json:"tenant"`
+
+	// Attributes, Principal and Resource are passed to acl.Request
+	Attributes map[string]any `json:"attributes"`
+	Principal  string         `json:"principal"`
+	Resource   string         `json:"resource"`
+
+	// Data is a document to filter with the Filters of the matched policies
+	Data any `json:"data"`
+}
+
+// Result is the decision returned for paths other than ".../allow"
+type Result struct {
+	Allow bool `json:"allow"`
+
+	// Data is Input.Data reduced to the fields the subjects may see, as
+	// Permission.Filter does, nil when denied
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// Error is the body of a failed request, in the format of OPA
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// OPA error codes
+const (
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInternal         = "internal_error"
+)
+
+// Handler serves the OPA data API, POST /v1/data/<path> with {"input": {...}},
+// answering every path with a check of the access control
+//
+// Paths ending in "/allow" return {"result": true|false} like a boolean rule,
+// other paths return {"result": Result}
+type Handler struct {
+	ac *acl.AccessControl
+}
+
+// NewHandler creates a Handler checking requests against ac
+func NewHandler(ac *acl.AccessControl) *Handler {
+	return &Handler{ac: ac}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := dataPath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeInvalidParameter, fmt.Sprintf("path %s is not served", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, CodeInvalidParameter, fmt.Sprintf("method %s is not supported", r.Method))
+		return
+	}
+
+	var body struct {
+		Input *Input `json:"input"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes)).Decode(&body); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, CodeInvalidParameter, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if body.Input == nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, "input is required")
+		return
+	}
+	if err := body.Input.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	res, err := h.decide(r, path, body.Input)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	var result any = res
+	if strings.HasSuffix(path, "/allow") {
+		result = res.Allow
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+// Names accepted from inputs, "editor:own" or "folder/42/document"
+// Drivers match policy keys as regular expressions, so the names exclude
+// their operators and the "@" tenant separator
+var (
+	inputName   = regexp.MustCompile(`^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$`)
+	inputObject = regexp.MustCompile(`^[A-Za-z0-9_-]+(/[A-Za-z0-9_-]+)*(:[A-Za-z0-9_-]+)*$`)
+	inputTenant = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`) // Tenants are compared as strings
+)
+
+func (in *Input) validate() error {
+	switch {
+	case in.Subject == "" && len(in.Subjects) == 0:
+		return fmt.Errorf("input.subject or input.subjects is required")
+	case in.Action == "":
+		return fmt.Errorf("input.action is required")
+	case in.Object == "":
+		return fmt.Errorf("input.object is required")
+	}
+
+	if in.Subject != "" && !inputName.MatchString(in.Subject) {
+		return fmt.Errorf("input.subject %q is not a valid subject", in.Subject)
+	}
+	for _, s := range in.Subjects {
+		if !inputName.MatchString(s) {
+			return fmt.Errorf("input.subjects %q is not a valid subject", s)
+		}
+	}
+	switch {
+	case !inputName.MatchString(in.Action):
+		return fmt.Errorf("input.action %q is not a valid action", in.Action)
+	case !inputObject.MatchString(in.Object):
+		return fmt.Errorf("input.object %q is not a valid object", in.Object)
+	case in.Tenant != "" && !inputTenant.MatchString(in.Tenant):
+		return fmt.Errorf("input.tenant %q is not a valid tenant", in.Tenant)
+	}
+	return nil
+}
+
+// decide checks the input and filters its data
+func (h *Handler) decide(r *http.Request, path string, in *Input) (Result, error) {
+	ac := h.ac
+	if in.Tenant != "" {
+		ac = ac.Tenant(in.Tenant)
+	}
+
+	subjects := in.Subjects
+	if in.Subject != "" {
+		subjects = append([]string{in.Subject}, subjects...)
+	}
+	strict := ac.Options().Strict
+	if in.Strict != nil {
+		strict = *in.Strict
+	}
+
+	perm, err := ac.CheckRequest(acl.Request{
+		Subjects:   subjects,
+		Action:     in.Action,
+		Object:     in.Object,
+		Strict:     strict,
+		Metadata:   map[string]any{"path": path},
+		Attributes: in.Attributes,
+		Principal:  in.Principal,
+		Resource:   in.Resource,
+		Context:    r.Context(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Allow: perm.Granted()}
+	if in.Data != nil && res.Allow {
+		if res.Data, err = perm.Filter(in.Data); err != nil {
+			return Result{}, fmt.Errorf("failed to filter data: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// dataPath returns the rule path of a data API URL path, "" for the whole document
+func dataPath(urlPath string) (string, bool) {
+	rest, ok := strings.CutPrefix(urlPath, DataPrefix)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return "", false
+	}
+	return strings.TrimSuffix(rest, "/"), true
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, Error{Code: code, Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
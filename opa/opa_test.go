@@ -0,0 +1,127 @@
+package opa
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Filters: []string{"id", "title"}},
+		{Subject: "writer", Action: "read", Object: "article", Fields: []string{"title"}},
+		{Subject: "admin", Action: "*", Object: "article"},
+		{Subject: "editor", Action: "update", Object: "article", Tenant: "acme"},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(ac))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, path, input string) (int, string) {
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(input))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestHandler_Allow(t *testing.T) {
+	srv := newServer(t)
+
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"granted", `{"subject": "user", "action": "read", "object": "article"}`, `{"result":true}`},
+		{"denied", `{"subject": "user", "action": "delete", "object": "article"}`, `{"result":false}`},
+		{"any subject", `{"subjects": ["user", "admin"], "action": "delete", "object": "article"}`, `{"result":true}`},
+		{"tenant", `{"subject": "editor", "action": "update", "object": "article", "tenant": "acme"}`, `{"result":true}`},
+		{"other tenant", `{"subject": "editor", "action": "update", "object": "article", "tenant": "globex"}`, `{"result":false}`},
+		{"no substring match", `{"subject": "min", "action": "delete", "object": "article"}`, `{"result":false}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := post(t, srv, "/v1/data/authz/allow", `{"input": `+tc.input+`}`)
+			assert.Equal(t, http.StatusOK, status)
+			assert.JSONEq(t, tc.want, body)
+		})
+	}
+}
+
+func TestHandler_Result(t *testing.T) {
+	srv := newServer(t)
+
+	status, body := post(t, srv, "/v1/data/authz", `{"input": {
+		"subject": "user", "action": "read", "object": "article",
+		"data": {"id": 1, "title": "Hello", "password": "secret"}
+	}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"result": {"allow": true, "data": {"id": 1, "title": "Hello"}}}`, body)
+
+	// Fields restrict writes, not what the subjects see
+	status, body = post(t, srv, "/v1/data/authz", `{"input": {
+		"subject": "writer", "action": "read", "object": "article",
+		"data": {"id": 1, "title": "Hello"}
+	}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"result": {"allow": true, "data": {"id": 1, "title": "Hello"}}}`, body)
+
+	status, body = post(t, srv, "/v1/data", `{"input": {
+		"subject": "guest", "action": "read", "object": "article",
+		"data": {"id": 1, "title": "Hello"}
+	}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"result": {"allow": false}}`, body)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	srv := newServer(t)
+
+	status, body := post(t, srv, "/v1/data/authz/allow", `{}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.JSONEq(t, `{"code": "invalid_parameter", "message": "input is required"}`, body)
+
+	status, body = post(t, srv, "/v1/data/authz/allow", `{"input": {"subject": "user", "object": "article"}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.JSONEq(t, `{"code": "invalid_parameter", "message": "input.action is required"}`, body)
+
+	// Names are matched as regular expressions by drivers and are never passed on as such
+	for _, input := range []string{
+		`{"subject": ".*", "action": "delete", "object": "article"}`,
+		`{"subjects": ["user", "adm.n"], "action": "delete", "object": "article"}`,
+		`{"subject": "user", "action": "read|delete", "object": "article"}`,
+		`{"subject": "user", "action": "read", "object": "art.*"}`,
+		`{"subject": "user", "action": "read", "object": "article", "tenant": "ac|me"}`,
+	} {
+		status, _ = post(t, srv, "/v1/data/authz/allow", `{"input": `+input+`}`)
+		assert.Equal(t, http.StatusBadRequest, status, input)
+	}
+
+	status, _ = post(t, srv, "/v1/data/authz/allow", `{"input":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	large := `{"input": {"subject": "user", "action": "read", "object": "article", "data": "` + strings.Repeat("x", MaxBodyBytes) + `"}}`
+	status, _ = post(t, srv, "/v1/data/authz", large)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	status, _ = post(t, srv, "/v1/policies", `{"input": {}}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := http.Get(srv.URL + "/v1/data/authz/allow")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
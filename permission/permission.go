@@ -1,6 +1,9 @@
 package permission
 
-import "github.com/alipourhabibi/abacl-go/grant"
+import (
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
 
 // Permission represents the result of an access control check
 type Permission struct {
@@ -31,6 +34,14 @@ func (p *Permission) Grant() *grant.Grant {
 	return p.grant
 }
 
+// Obligations returns the obligations and advice of the matched policies, nil when denied
+func (p *Permission) Obligations() []policy.Obligation {
+	if !p.granted || p.grant == nil {
+		return nil
+	}
+	return policy.Obligations(p.grant.Policies())
+}
+
 // Field is a convenience method to filter fields using the grant
 func (p *Permission) Field(data any) (map[string]any, error) {
 	if !p.granted || p.grant == nil {
@@ -46,3 +57,56 @@ func (p *Permission) Filter(data any) (map[string]any, error) {
 	}
 	return p.grant.Filter(data)
 }
+
+// Rows is a convenience method to select the records permitted by the grant's conditions
+func (p *Permission) Rows(records []map[string]any, attrs map[string]any) ([]map[string]any, error) {
+	if !p.granted || p.grant == nil {
+		return nil, nil
+	}
+	return p.grant.Rows(records, attrs)
+}
+
+// FieldList is a convenience method to filter fields of every list element using the grant
+func (p *Permission) FieldList(data any) ([]map[string]any, error) {
+	if !p.granted || p.grant == nil {
+		return nil, nil
+	}
+	return p.grant.FieldList(data)
+}
+
+// FilterList is a convenience method to apply data filters to every list element using the grant
+func (p *Permission) FilterList(data any) ([]map[string]any, error) {
+	if !p.granted || p.grant == nil {
+		return nil, nil
+	}
+	return p.grant.FilterList(data)
+}
+
+// FieldInto is a convenience function to zero the fields of in that cannot be modified
+// A denied permission returns the zero value of T
+func FieldInto[T any](p *Permission, in T) (T, error) {
+	if !p.granted || p.grant == nil {
+		var zero T
+		return zero, nil
+	}
+	return grant.FieldInto(p.grant, in)
+}
+
+// FilterInto is a convenience function to zero the fields of in that cannot be seen
+// A denied permission returns the zero value of T
+func FilterInto[T any](p *Permission, in T) (T, error) {
+	if !p.granted || p.grant == nil {
+		var zero T
+		return zero, nil
+	}
+	return grant.FilterInto(p.grant, in)
+}
+
+// ValidateFields is a convenience method to reject writes to fields the grant does not allow
+// A denied permission forbids every field
+func (p *Permission) ValidateFields(data any) error {
+	if !p.granted || p.grant == nil {
+		return grant.Forbidden(data, nil)
+	}
+	return p.grant.ValidateFields(data)
+}
@@ -0,0 +1,251 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator compares a record field with a condition value
+type Operator string
+
+const (
+	OpEq  Operator = "="
+	OpNe  Operator = "!="
+	OpLt  Operator = "<"
+	OpLte Operator = "<="
+	OpGt  Operator = ">"
+	OpGte Operator = ">="
+	OpIn  Operator = "in"
+)
+
+// Condition restricts the rows (records) a policy applies to
+type Condition struct {
+	Field string   // Record field, dot notation for nested values: "owner", "meta.tenant"
+	Op    Operator // Comparison operator
+	Value any      // Literal value, or "$name" to reference a caller attribute
+}
+
+// Validate checks if the condition is well-formed
+func (c *Condition) Validate() error {
+	if c.Field == "" {
+		return fmt.Errorf("condition field cannot be empty")
+	}
+
+	switch c.Op {
+	case OpEq, OpNe:
+	case OpLt, OpLte, OpGt, OpGte:
+		if c.Value == nil {
+			return fmt.Errorf("condition %s %s requires a value", c.Field, c.Op)
+		}
+	case OpIn:
+		if isReference(c.Value) {
+			return nil
+		}
+		if _, ok := Values(c.Value); !ok {
+			return fmt.Errorf("condition %s in requires a list value", c.Field)
+		}
+	default:
+		return fmt.Errorf("condition %s has unknown operator %q", c.Field, c.Op)
+	}
+
+	return nil
+}
+
+// Resolve returns the condition value, looking up attribute references in attrs
+func (c *Condition) Resolve(attrs map[string]any) (any, error) {
+	s, ok := c.Value.(string)
+	if !ok || !strings.HasPrefix(s, "$") {
+		return c.Value, nil
+	}
+	if strings.HasPrefix(s, "$$") {
+		// "$$" escapes a literal dollar sign
+		return s[1:], nil
+	}
+
+	name := s[1:]
+	v, ok := Lookup(attrs, name)
+	if !ok {
+		return nil, fmt.Errorf("condition %s references unknown attribute %q", c.Field, name)
+	}
+	return v, nil
+}
+
+// Match reports whether the record satisfies the condition
+// A missing or nil record field never matches a non-nil value, like NULL in SQL
+func (c *Condition) Match(record map[string]any, attrs map[string]any) (bool, error) {
+	want, err := c.Resolve(attrs)
+	if err != nil {
+		return false, err
+	}
+	got, _ := Lookup(record, c.Field)
+
+	if want == nil {
+		switch c.Op {
+		case OpEq:
+			return got == nil, nil
+		case OpNe:
+			return got != nil, nil
+		}
+		return false, nil
+	}
+	if got == nil {
+		return false, nil
+	}
+
+	switch c.Op {
+	case OpIn:
+		values, ok := Values(want)
+		if !ok {
+			return false, fmt.Errorf("condition %s in requires a list value", c.Field)
+		}
+		for _, v := range values {
+			if cmp, ok := compare(got, v); ok && cmp == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpEq:
+		cmp, ok := compare(got, want)
+		return ok && cmp == 0, nil
+	case OpNe:
+		cmp, ok := compare(got, want)
+		return !ok || cmp != 0, nil
+	}
+
+	cmp, ok := compare(got, want)
+	if !ok {
+		return false, nil
+	}
+	switch c.Op {
+	case OpLt:
+		return cmp < 0, nil
+	case OpLte:
+		return cmp <= 0, nil
+	case OpGt:
+		return cmp > 0, nil
+	case OpGte:
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("condition %s has unknown operator %q", c.Field, c.Op)
+}
+
+// Lookup finds a value by key, falling back to dot notation for nested maps
+func Lookup(data map[string]any, path string) (any, bool) {
+	if v, ok := data[path]; ok {
+		return v, true
+	}
+
+	parts := strings.Split(path, ".")
+	var cur any = data
+	for _, part := range parts {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func isReference(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, "$") && !strings.HasPrefix(s, "$$")
+}
+
+// Values converts the supported list types to []any
+func Values(v any) ([]any, bool) {
+	switch vs := v.(type) {
+	case []any:
+		return vs, true
+	case []string:
+		out := make([]any, len(vs))
+		for i, s := range vs {
+			out[i] = s
+		}
+		return out, true
+	case []int:
+		out := make([]any, len(vs))
+		for i, n := range vs {
+			out[i] = n
+		}
+		return out, true
+	case []int64:
+		out := make([]any, len(vs))
+		for i, n := range vs {
+			out[i] = n
+		}
+		return out, true
+	case []float64:
+		out := make([]any, len(vs))
+		for i, n := range vs {
+			out[i] = n
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// compare orders two scalar values, numbers are compared by value regardless of type
+// and booleans as 0 and 1, matching how SQL databases store them
+func compare(a, b any) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Obligation is a duty the application must fulfill when a policy grants access,
+// e.g. "log to compliance", "require recent MFA" or "watermark the PDF"
+type Obligation struct {
+	Type   string         // Handler name, e.g. "watermark"
+	Params map[string]any // Handler parameters, e.g. {"text": "confidential"}
+
+	// Advice marks an optional obligation the application may ignore
+	Advice bool
+}
+
+// Validate checks if the obligation is well-formed
+func (o *Obligation) Validate() error {
+	if o.Type == "" {
+		return fmt.Errorf("obligation type cannot be empty")
+	}
+	return nil
+}
+
+// Obligations collects the obligations of policies, dropping duplicates
+func Obligations(policies []Policy) []Obligation {
+	var out []Obligation
+	for _, p := range policies {
+		for _, o := range p.Obligations {
+			if !containsObligation(out, o) {
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+func containsObligation(obligations []Obligation, o Obligation) bool {
+	for _, x := range obligations {
+		if reflect.DeepEqual(x, o) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,21 +2,45 @@ package policy
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// GlobalTenant holds platform-wide policies shared by every tenant
+const GlobalTenant = ""
+
+// TenantSeparator prefixes the keys of tenant policies with their tenant: "acme@user:NULL:..."
+// Subjects, actions and objects cannot contain it, so keys of different tenants never collide
+const TenantSeparator = "@"
+
+var (
+	tenantName   = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	relationName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
 // Policy defines an access control rule
 type Policy struct {
+	Tenant  string // e.g., "acme", empty for the global tenant
 	Subject string // e.g., "user", "admin:readonly"
 	Action  string // e.g., "read", "create:own"
-	Object  string // e.g., "article", "article:published"
+	Object  string // e.g., "article", "article:published", "org/{orgId}/project/*"
+
+	// Cascade extends the policy to the children of its object path:
+	// a policy on "org/42" also matches "org/42/project/7"
+	Cascade bool
+
+	// Relation the checked principal must hold on the checked resource,
+	// e.g. "viewer", evaluated by the access control's relationship checker
+	Relation string
 
 	// Optional constraints
 	TimeWindows []TimeWindow
-	Fields      []string // Field filters: ["*", "!password"]
-	Filters     []string // Data filters
-	Locations   []string // IP/CIDR restrictions
+	Fields      []string     // Field filters: ["*", "!password"]
+	Filters     []string     // Data filters
+	Conditions  []Condition  // Row constraints: ownership, status, tenant
+	Locations   []string     // IP/CIDR restrictions
+	Obligations []Obligation // Duties returned with the decision when granted
 }
 
 type TimeWindow struct {
@@ -35,57 +59,103 @@ func (p *Policy) Validate() error {
 	if p.Object == "" {
 		return fmt.Errorf("policy object cannot be empty")
 	}
+	if p.Tenant != GlobalTenant && !tenantName.MatchString(p.Tenant) {
+		return fmt.Errorf("policy tenant can only contain letters, digits, '_', '.' and '-'")
+	}
 
-	// Validate no more than one colon
-	if strings.Count(p.Subject, ":") > 1 {
-		return fmt.Errorf("policy subject can contain at most one colon")
+	if p.Relation != "" && !relationName.MatchString(p.Relation) {
+		return fmt.Errorf("policy relation must be an identifier")
 	}
-	if strings.Count(p.Action, ":") > 1 {
-		return fmt.Errorf("policy action can contain at most one colon")
+
+	for _, prop := range [][2]string{{"subject", p.Subject}, {"action", p.Action}, {"object", p.Object}} {
+		if strings.Contains(prop[1], TenantSeparator) {
+			return fmt.Errorf("policy %s cannot contain %q", prop[0], TenantSeparator)
+		}
 	}
-	if strings.Count(p.Object, ":") > 1 {
-		return fmt.Errorf("policy object can contain at most one colon")
+
+	if err := validateScopes("subject", p.Subject); err != nil {
+		return err
+	}
+	if err := validateScopes("action", p.Action); err != nil {
+		return err
+	}
+	if err := validateScopes("object", p.Object); err != nil {
+		return err
+	}
+	if err := validateWildcard("subject", p.Subject); err != nil {
+		return err
+	}
+	if err := validateWildcard("action", p.Action); err != nil {
+		return err
+	}
+	if err := validateWildcard("object", p.Object); err != nil {
+		return err
+	}
+
+	for _, o := range p.Obligations {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("invalid policy obligation: %w", err)
+		}
+	}
+
+	for _, c := range p.Conditions {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("invalid policy condition: %w", err)
+		}
 	}
 
 	return nil
 }
 
 // Key generates a unique identifier for this policy using your original format
+// Nested scopes are joined with ScopeSeparator: "read:own:draft" is keyed "read:own.draft"
 func (p *Policy) Key() string {
-	subs := strings.Split(p.Subject, ":")
-	if len(subs) == 1 {
-		subs = append(subs, "NULL")
-	}
-	objs := strings.Split(p.Object, ":")
-	if len(objs) == 1 {
-		objs = append(objs, "ANY")
+	key := fmt.Sprintf("%s:%s:%s",
+		keyComponent(p.Subject, NoSubjectScope),
+		keyComponent(p.Action, NoActionScope),
+		keyComponent(p.Object, NoObjectScope),
+	)
+	if p.Tenant != GlobalTenant {
+		key = p.Tenant + TenantSeparator + key
 	}
-	acts := strings.Split(p.Action, ":")
-	if len(acts) == 1 {
-		acts = append(acts, "ALL")
-	}
-	key := fmt.Sprintf("%s:%s:%s:%s:%s:%s", subs[0], subs[1], acts[0], acts[1], objs[0], objs[1])
 	return key
 }
 
+// keyComponent encodes a subject, action or object as "base:scope" for a key
+func keyComponent(prop, placeholder string) string {
+	base, scopes, ok := strings.Cut(prop, ":")
+	if !ok {
+		return base + ":" + placeholder
+	}
+	return base + ":" + strings.ReplaceAll(scopes, ":", ScopeSeparator)
+}
+
 // Strictify converts a policy to use regex wildcards for non-strict matching
+// The last scope level is relaxed: "read" and "read:own" match every scope of read,
+// "read:own:draft" matches "read:own" and every scope nested in it
 func (p *Policy) Strictify() Policy {
-	subs := strings.Split(p.Subject, ":")
-	if len(subs) == 1 {
-		subs = append(subs, "NULL")
-	}
-	objs := strings.Split(p.Object, ":")
-	if len(objs) == 1 {
-		objs = append(objs, "ANY")
+	return Policy{
+		Tenant:  p.Tenant,
+		Subject: relaxScope(p.Subject),
+		Object:  relaxScope(p.Object),
+		Action:  relaxScope(p.Action),
 	}
-	acts := strings.Split(p.Action, ":")
-	if len(acts) == 1 {
-		acts = append(acts, "ALL")
+}
+
+// relaxScope keeps the base and the parent scope levels, the rest matches any scope
+func relaxScope(prop string) string {
+	levels := strings.Split(prop, ":")
+
+	var parents []string
+	if len(levels) > 2 {
+		for _, l := range levels[1 : len(levels)-1] {
+			parents = append(parents, regexp.QuoteMeta(l))
+		}
 	}
 
-	return Policy{
-		Subject: fmt.Sprintf("%s:%s", subs[0], "\\w+"),
-		Object:  fmt.Sprintf("%s:%s", objs[0], "\\w+"),
-		Action:  fmt.Sprintf("%s:%s", acts[0], "\\w+"),
+	nested := `(\.\w+)*`
+	if len(parents) == 0 {
+		return levels[0] + ":" + `\w+` + nested
 	}
+	return levels[0] + ":" + strings.Join(parents, `\.`) + nested
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPolicyKey(t *testing.T) {
@@ -39,6 +40,25 @@ func TestPolicyKey(t *testing.T) {
 			},
 			expected: "admin:NULL:read:own:article:published",
 		},
+		{
+			name: "tenant policy",
+			policy: Policy{
+				Tenant:  "acme",
+				Subject: "user",
+				Action:  "read",
+				Object:  "article",
+			},
+			expected: "acme@user:NULL:read:ALL:article:ANY",
+		},
+		{
+			name: "nested scopes",
+			policy: Policy{
+				Subject: "user",
+				Action:  "read:own:draft",
+				Object:  "article:published:featured",
+			},
+			expected: "user:NULL:read:own.draft:article:published.featured",
+		},
 	}
 
 	for _, tt := range tests {
@@ -63,7 +83,7 @@ func TestPolicyStrictify(t *testing.T) {
 				Action:  "read",
 				Object:  "article",
 			},
-			expected: "user:\\w+:read:\\w+:article:\\w+",
+			expected: `user:\w+(\.\w+)*:read:\w+(\.\w+)*:article:\w+(\.\w+)*`,
 		},
 		{
 			name: "strictify with scope",
@@ -72,7 +92,16 @@ func TestPolicyStrictify(t *testing.T) {
 				Action:  "read:own",
 				Object:  "article",
 			},
-			expected: "user:\\w+:read:\\w+:article:\\w+",
+			expected: `user:\w+(\.\w+)*:read:\w+(\.\w+)*:article:\w+(\.\w+)*`,
+		},
+		{
+			name: "strictify nested scopes",
+			policy: Policy{
+				Subject: "user",
+				Action:  "read:own:draft",
+				Object:  "article:published:featured",
+			},
+			expected: `user:\w+(\.\w+)*:read:own(\.\w+)*:article:published(\.\w+)*`,
 		},
 	}
 
@@ -129,14 +158,122 @@ func TestPolicyValidation(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "too many colons in subject",
+			name: "nested scopes",
 			policy: Policy{
 				Subject: "user:admin:super",
+				Action:  "read:own:draft",
+				Object:  "article:published:featured",
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty nested scope",
+			policy: Policy{
+				Subject: "user",
+				Action:  "read:own:",
+				Object:  "article",
+			},
+			wantErr: true,
+		},
+		{
+			name: "scope with separator",
+			policy: Policy{
+				Subject: "user",
+				Action:  "read:own.draft",
+				Object:  "article",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tenant",
+			policy: Policy{
+				Tenant:  "acme@corp",
+				Subject: "user",
 				Action:  "read",
 				Object:  "article",
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid condition",
+			policy: Policy{
+				Subject:    "user",
+				Action:     "read",
+				Object:     "article",
+				Conditions: []Condition{{Field: "owner", Op: OpEq, Value: "$user.id"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown condition operator",
+			policy: Policy{
+				Subject:    "user",
+				Action:     "read",
+				Object:     "article",
+				Conditions: []Condition{{Field: "owner", Op: "like", Value: "j%"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "in condition without list",
+			policy: Policy{
+				Subject:    "user",
+				Action:     "read",
+				Object:     "article",
+				Conditions: []Condition{{Field: "status", Op: OpIn, Value: "draft"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "wildcard subject",
+			policy:  Policy{Subject: "*", Action: "*", Object: "reports/*:own"},
+			wantErr: false,
+		},
+		{
+			name:    "dotted glob object",
+			policy:  Policy{Subject: "admin", Action: "read", Object: "billing.*"},
+			wantErr: false,
+		},
+		{
+			name:    "regex in wildcard",
+			policy:  Policy{Subject: "admin", Action: "read", Object: "report.+*"},
+			wantErr: true,
+		},
+		{
+			name:    "consecutive wildcards",
+			policy:  Policy{Subject: "admin", Action: "read", Object: "reports/q**"},
+			wantErr: true,
+		},
+		{
+			name:    "object path with parameters",
+			policy:  Policy{Subject: "member", Action: "read", Object: "org/{orgId}/project/*/**", Cascade: true},
+			wantErr: false,
+		},
+		{
+			name:    "parameter inside a segment",
+			policy:  Policy{Subject: "member", Action: "read", Object: "org/id-{orgId}"},
+			wantErr: true,
+		},
+		{
+			name:    "parameter in action",
+			policy:  Policy{Subject: "member", Action: "{verb}", Object: "org"},
+			wantErr: true,
+		},
+		{
+			name:    "wildcard scope",
+			policy:  Policy{Subject: "admin", Action: "read:*", Object: "article"},
+			wantErr: true,
+		},
+		{
+			name:    "tenant separator in subject",
+			policy:  Policy{Subject: "acme@user", Action: "read", Object: "article"},
+			wantErr: true,
+		},
+		{
+			name:    "tenant separator in object scope",
+			policy:  Policy{Subject: "user", Action: "read", Object: "article:x@y"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -150,3 +287,95 @@ func TestPolicyValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchBase(t *testing.T) {
+	tests := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"*", "reports/2024/q1", true},
+		{"reports/*", "reports/q1", true},
+		{"reports/*", "reports/2024/q1", false},
+		{"reports/*", "invoices/q1", false},
+		{"billing.*", "billing.invoice", true},
+		{"billing.*", "billingXinvoice", false},
+		{"*-report", "sales-report", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"article", "article", true},
+		{"org/**/document/*", "org/42/project/7/document/9", true},
+		{"org/**", "org", true},
+		{"org/{orgId}/project/*", "org/42/project/7", true},
+		{"org/{orgId}/project/*", "org/42/project/7/document/9", false},
+		{"org/{id}/team/{id}", "org/42/team/7", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchBase(tt.glob, tt.name), "%s ~ %s", tt.glob, tt.name)
+	}
+}
+
+func TestMostSpecific(t *testing.T) {
+	star := Policy{Subject: "admin", Action: "*", Object: "*"}
+	glob := Policy{Subject: "admin", Action: "read", Object: "reports/*"}
+	exact := Policy{Subject: "admin", Action: "read", Object: "reports/q1"}
+	scoped := Policy{Subject: "admin", Action: "read:own", Object: "reports/q1"}
+
+	assert.Equal(t, []Policy{exact, scoped}, MostSpecific([]Policy{star, exact, glob, scoped}))
+	assert.Equal(t, []Policy{glob}, MostSpecific([]Policy{star, glob}))
+	assert.Equal(t, []Policy{star}, MostSpecific([]Policy{star}))
+}
+
+func TestInstantiate(t *testing.T) {
+	p := Policy{Subject: "admin", Action: "*", Object: "reports/*:own"}
+
+	inst, ok := p.Instantiate(Policy{Subject: "admin", Action: "delete", Object: "reports/q1"})
+	assert.True(t, ok)
+	assert.Equal(t, "admin:NULL:delete:ALL:reports/q1:own", inst.Key())
+
+	_, ok = p.Instantiate(Policy{Subject: "admin", Action: "delete", Object: "invoices/q1"})
+	assert.False(t, ok)
+}
+
+func TestCaptures(t *testing.T) {
+	p := Policy{Subject: "member", Action: "read", Object: "org/{orgId}/project/{projectId}", Cascade: true}
+
+	captures, ok := p.Captures("org/42/project/7/document/9")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"orgId": "42", "projectId": "7"}, captures)
+
+	_, ok = p.Captures("org/42")
+	assert.False(t, ok)
+
+	assert.True(t, p.MatchAttributes("org/42/project/7", map[string]any{"orgId": 42, "projectId": "7"}))
+	assert.False(t, p.MatchAttributes("org/42/project/7", map[string]any{"orgId": 43, "projectId": "7"}))
+	assert.False(t, p.MatchAttributes("org/42/project/7", map[string]any{"orgId": 42}))
+}
+
+func TestParseKey(t *testing.T) {
+	for _, p := range []Policy{
+		{Subject: "user", Action: "read", Object: "article"},
+		{Tenant: "acme", Subject: "admin:readonly", Action: "read:own:draft", Object: "org/42:published:featured"},
+	} {
+		parsed, err := ParseKey(p.Key())
+		assert.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+
+	_, err := ParseKey("user:NULL:read")
+	assert.Error(t, err)
+}
+
+func TestPolicyKey_TenantCollision(t *testing.T) {
+	tenant := Policy{Tenant: "acme", Subject: "user", Action: "read", Object: "article"}
+	global := Policy{Subject: "acme@user", Action: "read", Object: "article"}
+
+	// Without validation both would be keyed "acme@user:NULL:read:ALL:article:ANY"
+	require.Equal(t, tenant.Key(), global.Key())
+	assert.NoError(t, tenant.Validate())
+	assert.Error(t, global.Validate())
+
+	parsed, err := ParseKey(tenant.Key())
+	require.NoError(t, err)
+	assert.Equal(t, tenant, parsed)
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeSeparator joins nested scope levels in keys
+const ScopeSeparator = "."
+
+// Key placeholders for components without a scope
+const (
+	NoSubjectScope = "NULL"
+	NoActionScope  = "ALL"
+	NoObjectScope  = "ANY"
+)
+
+// Scopes returns the scope levels of a subject, action or object:
+// "read:own:draft" has the scopes "own" and "draft"
+func Scopes(prop string) []string {
+	levels := strings.Split(prop, ":")
+	return levels[1:]
+}
+
+// validateScopes checks the scope levels of a subject, action or object
+func validateScopes(prop, value string) error {
+	for _, level := range Scopes(value) {
+		if level == "" {
+			return fmt.Errorf("policy %s cannot contain an empty scope", prop)
+		}
+		if strings.Contains(level, ScopeSeparator) {
+			return fmt.Errorf("policy %s scope cannot contain %q", prop, ScopeSeparator)
+		}
+	}
+	return nil
+}
+
+// ParseKey rebuilds the tenant, subject, action and object of a policy from its key
+// Drivers storing only keys can use it to rebuild their policies; keys in the
+// current format only, driver.Migrate re-keys stored policies instead
+func ParseKey(key string) (Policy, error) {
+	var p Policy
+	if tenant, rest, ok := strings.Cut(key, TenantSeparator); ok {
+		p.Tenant, key = tenant, rest
+	}
+
+	parts := strings.Split(key, ":")
+	if len(parts) != 6 {
+		return Policy{}, fmt.Errorf("invalid policy key %q", key)
+	}
+
+	p.Subject = parseComponent(parts[0], parts[1], NoSubjectScope)
+	p.Action = parseComponent(parts[2], parts[3], NoActionScope)
+	p.Object = parseComponent(parts[4], parts[5], NoObjectScope)
+	return p, nil
+}
+
+func parseComponent(base, scopes, placeholder string) string {
+	if scopes == placeholder {
+		return base
+	}
+	return base + ":" + strings.ReplaceAll(scopes, ScopeSeparator, ":")
+}
@@ -0,0 +1,289 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Wildcard matches every subject, action or object when used as a base
+const Wildcard = "*"
+
+// Deep matches any number of path segments when used as a segment of an object
+const Deep = "**"
+
+var (
+	wildcardSegment = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+	paramSegment    = regexp.MustCompile(`^\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+)
+
+// Specificity ranks how precisely a policy names its subject, action and object
+// Exact bases outrank globs such as "reports/*", which outrank the bare "*"
+type Specificity struct {
+	Exact int // Number of bases without wildcards
+	Globs int // Number of bases mixing names and wildcards, parameters or cascading
+}
+
+// Less reports whether s is less specific than o
+func (s Specificity) Less(o Specificity) bool {
+	if s.Exact != o.Exact {
+		return s.Exact < o.Exact
+	}
+	return s.Globs < o.Globs
+}
+
+// base returns the part of a component before its scope
+func base(prop string) string {
+	b, _, _ := strings.Cut(prop, ":")
+	return b
+}
+
+// IsWildcard reports whether a subject, action or object base contains
+// wildcards or path parameters
+func IsWildcard(name string) bool {
+	return strings.ContainsAny(base(name), Wildcard+"{")
+}
+
+// HasWildcards reports whether any base of the policy contains wildcards
+func (p *Policy) HasWildcards() bool {
+	return IsWildcard(p.Subject) || IsWildcard(p.Action) || IsWildcard(p.Object)
+}
+
+// IsPattern reports whether the policy matches other names than its own,
+// through wildcards or by cascading to child objects
+func (p *Policy) IsPattern() bool {
+	return p.Cascade || p.HasWildcards()
+}
+
+// Specificity ranks the policy against other policies matching the same check
+func (p *Policy) Specificity() Specificity {
+	var s Specificity
+	for i, prop := range []string{p.Subject, p.Action, p.Object} {
+		switch b := base(prop); {
+		case i == 2 && p.Cascade && b != Wildcard:
+			s.Globs++
+		case !IsWildcard(b):
+			s.Exact++
+		case b != Wildcard:
+			s.Globs++
+		}
+	}
+	return s
+}
+
+// validateWildcard checks the wildcard syntax of a subject, action or object
+// Globs are plain names with '*', never regular expressions
+// Objects may also use "**" and "{param}" path segments
+func validateWildcard(prop, value string) error {
+	b, scope, _ := strings.Cut(value, ":")
+	if strings.ContainsAny(scope, Wildcard+"{}") {
+		return fmt.Errorf("policy %s scope cannot contain wildcards", prop)
+	}
+	if !strings.ContainsAny(b, Wildcard+"{}") {
+		return nil
+	}
+
+	for _, seg := range strings.Split(b, "/") {
+		if prop == "object" && (seg == Deep || paramSegment.MatchString(seg)) {
+			continue
+		}
+		if strings.Contains(seg, Deep) {
+			return fmt.Errorf("policy %s wildcard can only use '**' as a whole object path segment", prop)
+		}
+		if strings.ContainsAny(seg, "{}") {
+			if prop != "object" {
+				return fmt.Errorf("policy %s cannot contain path parameters", prop)
+			}
+			return fmt.Errorf("policy object parameter %q must be a whole path segment named like an identifier", seg)
+		}
+		if !wildcardSegment.MatchString(strings.ReplaceAll(seg, Wildcard, "")) {
+			return fmt.Errorf("policy %s wildcard can only contain letters, digits, '_', '-', '.', '/' and '*'", prop)
+		}
+	}
+	return nil
+}
+
+// MatchBase reports whether a base name matches a glob
+// The bare "*" matches any name, elsewhere '*' matches any run of characters except '/'
+// In paths "**" matches any number of segments and "{param}" any single segment
+func MatchBase(glob, name string) bool {
+	_, ok := matchPath(glob, name, false)
+	return ok
+}
+
+// matchPath matches a name against a glob, returning the captured parameters
+// With cascade the glob may also match a parent path of the name
+func matchPath(glob, name string, cascade bool) (map[string]string, bool) {
+	if glob == Wildcard {
+		return nil, true
+	}
+
+	captures := map[string]string{}
+	if !matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"), cascade, captures) {
+		return nil, false
+	}
+	return captures, true
+}
+
+func matchSegments(globs, names []string, cascade bool, captures map[string]string) bool {
+	if len(globs) == 0 {
+		return len(names) == 0 || cascade
+	}
+
+	if globs[0] == Deep {
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(globs[1:], names[i:], cascade, captures) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(names) == 0 {
+		return false
+	}
+
+	if m := paramSegment.FindStringSubmatch(globs[0]); m != nil {
+		if names[0] == "" {
+			return false
+		}
+		prev, seen := captures[m[1]]
+		if seen && prev != names[0] {
+			return false
+		}
+		captures[m[1]] = names[0]
+		if matchSegments(globs[1:], names[1:], cascade, captures) {
+			return true
+		}
+		if !seen {
+			delete(captures, m[1])
+		}
+		return false
+	}
+
+	return matchSegment(globs[0], names[0]) && matchSegments(globs[1:], names[1:], cascade, captures)
+}
+
+// matchSegment matches a single path segment, '*' matching any run of characters
+func matchSegment(glob, name string) bool {
+	parts := strings.Split(glob, Wildcard)
+	if len(parts) == 1 {
+		return glob == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+
+	last := len(parts) - 1
+	for i, part := range parts[1:] {
+		if i+1 == last {
+			return strings.HasSuffix(name, part)
+		}
+		// The earliest occurrence leaves the most room for the following parts
+		idx := strings.Index(name, part)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(part):]
+	}
+	return true
+}
+
+// Instantiate replaces the wildcard bases of the policy with those of q
+// It reports false when a wildcard does not match the base it replaces
+// The key of the result can then be matched against the key of q
+func (p Policy) Instantiate(q Policy) (Policy, bool) {
+	var ok bool
+	if p.Subject, ok = instantiate(p.Subject, q.Subject, false); !ok {
+		return p, false
+	}
+	if p.Action, ok = instantiate(p.Action, q.Action, false); !ok {
+		return p, false
+	}
+	if p.Object, ok = instantiate(p.Object, q.Object, p.Cascade); !ok {
+		return p, false
+	}
+	return p, true
+}
+
+func instantiate(glob, name string, cascade bool) (string, bool) {
+	gb, scope, scoped := strings.Cut(glob, ":")
+	if !cascade && !IsWildcard(gb) {
+		return glob, true
+	}
+
+	nb := base(name)
+	if _, ok := matchPath(gb, nb, cascade); !ok {
+		return glob, false
+	}
+	if scoped {
+		return nb + ":" + scope, true
+	}
+	return nb, true
+}
+
+// Captures returns the values of the object's "{param}" segments for an object path
+// It reports false when the policy's object does not match the path
+func (p *Policy) Captures(object string) (map[string]string, bool) {
+	return matchPath(base(p.Object), base(object), p.Cascade)
+}
+
+// MatchAttributes reports whether every parameter captured from the object path
+// equals the attribute of the same name, policies without parameters always match
+func (p *Policy) MatchAttributes(object string, attrs map[string]any) bool {
+	if !strings.Contains(p.Object, "{") {
+		return true
+	}
+
+	captures, ok := p.Captures(object)
+	if !ok {
+		return false
+	}
+	for name, value := range captures {
+		attr, ok := Lookup(attrs, name)
+		if !ok || attr == nil || fmt.Sprint(attr) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// MostSpecific keeps the policies of the highest Specificity
+// Exact matches thus take precedence over globs, and globs over "*"
+func MostSpecific(policies []Policy) []Policy {
+	if len(policies) < 2 {
+		return policies
+	}
+
+	best := policies[0].Specificity()
+	for _, p := range policies[1:] {
+		if s := p.Specificity(); best.Less(s) {
+			best = s
+		}
+	}
+
+	out := make([]Policy, 0, len(policies))
+	for _, p := range policies {
+		if p.Specificity() == best {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Sort orders policies most specific first, then by key
+// Drivers return Find results in this order, so checks and traces are reproducible
+func Sort(policies []Policy) {
+	slices.SortFunc(policies, func(a, b Policy) int {
+		sa, sb := a.Specificity(), b.Specificity()
+		switch {
+		case sb.Less(sa):
+			return -1
+		case sa.Less(sb):
+			return 1
+		}
+		return strings.Compare(a.Key(), b.Key())
+	})
+}
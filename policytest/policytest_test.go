@@ -0,0 +1,185 @@
+package policytest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuite_Load(t *testing.T) {
+	suite, err := Load("testdata/articles.yaml")
+	require.NoError(t, err)
+	assert.Len(t, suite.Policies, 3)
+	assert.Len(t, suite.Cases, 3)
+
+	result, err := suite.Run()
+	require.NoError(t, err)
+	assert.Zero(t, result.Failed())
+	assert.Equal(t, 3, result.Coverage.Total)
+	assert.Equal(t, []string{"admin:NULL:delete:ALL:article:ANY"}, result.Coverage.Unused)
+}
+
+func TestSuite_JSON(t *testing.T) {
+	suite, err := Parse([]byte(`{
+		"options": {"strict": true, "merge": "intersection"},
+		"policies": [
+			{"subject": "user", "action": "read:own", "object": "article", "filters": ["*", "!notes"]},
+			{"subject": "editor", "action": "read:own", "object": "article", "filters": ["title"]}
+		],
+		"cases": [
+			{
+				"name": "strict scope",
+				"subjects": ["user"], "action": "read", "object": "article",
+				"expect": "deny"
+			},
+			{
+				"name": "intersection of roles",
+				"subjects": ["user", "editor"], "action": "read:own", "object": "article",
+				"expect": "allow",
+				"data": {"title": "A", "notes": "x", "body": "y"},
+				"visible": ["title"]
+			}
+		]
+	}`))
+	require.NoError(t, err)
+
+	result, err := suite.Run()
+	require.NoError(t, err)
+	for _, c := range result.Cases {
+		assert.True(t, c.Passed(), "%s: %v", c.Case.Name, c.Failures)
+	}
+	assert.Empty(t, result.Coverage.Unused)
+}
+
+func TestSuite_Failures(t *testing.T) {
+	suite, err := Parse([]byte(`
+policies:
+  - subject: user
+    action: read
+    object: article
+    filters: ["*", "!password"]
+cases:
+  - name: wrong decision
+    subjects: [guest]
+    action: read
+    object: article
+    expect: allow
+  - name: wrong visible fields
+    subjects: [user]
+    action: read
+    object: article
+    expect: allow
+    data: {title: A, password: secret}
+    visible: [title, password]
+  - name: expectations without data
+    subjects: [user]
+    action: read
+    object: article
+    expect: allow
+    writable: [title]
+`))
+	require.NoError(t, err)
+
+	result, err := suite.Run()
+	require.NoError(t, err)
+	require.Len(t, result.Cases, 3)
+	assert.Equal(t, 3, result.Failed())
+
+	assert.Equal(t, []string{"expected allow, got deny"}, result.Cases[0].Failures)
+	assert.Equal(t, []string{"expected visible [password title], got [title]"}, result.Cases[1].Failures)
+	assert.Equal(t, []string{"user:NULL:read:ALL:article:ANY"}, result.Cases[1].Trace.Matched)
+	assert.Len(t, result.Cases[2].Failures, 1)
+
+	var buf bytes.Buffer
+	result.Write(&buf, false)
+	assert.Contains(t, buf.String(), "FAIL wrong decision")
+	assert.Contains(t, buf.String(), "trace: allow, matched [user:NULL:read:ALL:article:ANY]")
+	assert.Contains(t, buf.String(), "0 passed, 3 failed")
+}
+
+func TestSuite_InvalidExpect(t *testing.T) {
+	_, err := Parse([]byte(`cases: [{name: x, subjects: [user], action: read, object: article, expect: maybe}]`))
+	assert.Error(t, err)
+}
+
+func TestSuite_UnknownKeys(t *testing.T) {
+	for _, data := range []string{
+		`policies: [{subject: user, action: read, object: article, filter: ["*"]}]`,
+		`{"cases": [{"name": "x", "subject": ["user"], "action": "read", "object": "article", "expect": "allow"}]}`,
+		`option: {strict: true}`,
+	} {
+		_, err := Parse([]byte(data))
+		assert.ErrorContains(t, err, "not found in type", data)
+	}
+}
+
+func TestSuite_PolicyConstraints(t *testing.T) {
+	suite, err := Parse([]byte(`
+policies:
+  - subject: user
+    action: read
+    object: document
+    relation: viewer
+    filters: ["*"]
+    timeWindows: [{cron: "0 9 * * 1-5", duration: 8h}]
+    locations: [10.0.0.0/8]
+    obligations:
+      - {type: watermark, params: {text: confidential}}
+      - {type: notify-owner, advice: true}
+tuples:
+  - document:9#viewer@group:eng#member
+  - group:eng#member@user:bob
+cases:
+  - name: members of a viewing group read the document
+    subjects: [user]
+    principal: user:bob
+    resource: document:9
+    action: read
+    object: document
+    expect: allow
+  - name: others do not
+    subjects: [user]
+    principal: user:dave
+    resource: document:9
+    action: read
+    object: document
+    expect: deny
+`))
+	require.NoError(t, err)
+
+	p := suite.Policies[0].ToPolicy()
+	assert.Equal(t, "viewer", p.Relation)
+	assert.Equal(t, []policy.TimeWindow{{CronExpr: "0 9 * * 1-5", Duration: 8 * time.Hour}}, p.TimeWindows)
+	assert.Equal(t, []string{"10.0.0.0/8"}, p.Locations)
+	assert.Equal(t, []policy.Obligation{
+		{Type: "watermark", Params: map[string]any{"text": "confidential"}},
+		{Type: "notify-owner", Advice: true},
+	}, p.Obligations)
+
+	result, err := suite.Run()
+	require.NoError(t, err)
+	for _, c := range result.Cases {
+		assert.True(t, c.Passed(), "%s: %v", c.Case.Name, c.Failures)
+	}
+}
+
+func TestRun_ExistingAccessControl(t *testing.T) {
+	ac, err := acl.New([]policy.Policy{
+		{Tenant: "acme", Subject: "user", Action: "read", Object: "article"},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	result, err := Run(ac, false, []Case{
+		{Name: "tenant", Tenant: "acme", Subjects: []string{"user"}, Action: "read", Object: "article", Expect: "allow"},
+		{Name: "other tenant", Tenant: "globex", Subjects: []string{"user"}, Action: "read", Object: "article", Expect: "deny"},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, result.Failed())
+	assert.Empty(t, result.Coverage.Unused)
+}
@@ -0,0 +1,233 @@
+package policytest
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+)
+
+// Trace explains how a case was decided
+type Trace struct {
+	Granted  bool
+	Matched  []string // Keys of the matched policies
+	Visible  []string // Field paths left by Filter
+	Writable []string // Field paths left by Field
+	Err      error
+}
+
+// CaseResult is the outcome of a single case
+type CaseResult struct {
+	Case     Case
+	Failures []string
+	Trace    Trace
+}
+
+// Passed reports whether the case met every expectation
+func (r CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Coverage lists the stored policies no case matched
+type Coverage struct {
+	Total  int
+	Unused []string
+}
+
+// Result is the outcome of a suite run
+type Result struct {
+	Cases    []CaseResult
+	Coverage Coverage
+}
+
+// Failed returns the number of failed cases
+func (r *Result) Failed() int {
+	n := 0
+	for _, c := range r.Cases {
+		if !c.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Run loads the suite's policies into an in-memory access control and runs its cases
+func (s *Suite) Run() (*Result, error) {
+	ac, err := s.AccessControl()
+	if err != nil {
+		return nil, err
+	}
+	return Run(ac, s.Options.Strict, s.Cases)
+}
+
+// Run evaluates cases against an access control, strict is the default for
+// cases without their own strict setting
+func Run(ac *acl.AccessControl, strict bool, cases []Case) (*Result, error) {
+	stored, err := ac.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	result := &Result{}
+	for _, c := range cases {
+		r := runCase(ac, strict, c)
+		for _, key := range r.Trace.Matched {
+			used[key] = true
+		}
+		result.Cases = append(result.Cases, r)
+	}
+
+	result.Coverage.Total = len(stored)
+	for _, p := range stored {
+		if key := p.Key(); !used[key] {
+			result.Coverage.Unused = append(result.Coverage.Unused, key)
+		}
+	}
+	sort.Strings(result.Coverage.Unused)
+
+	return result, nil
+}
+
+func runCase(ac *acl.AccessControl, strict bool, c Case) CaseResult {
+	r := CaseResult{Case: c}
+	if c.Strict != nil {
+		strict = *c.Strict
+	}
+
+	view := ac
+	if c.Tenant != "" {
+		view = ac.Tenant(c.Tenant)
+	}
+
+	perm, err := view.CheckRequest(acl.Request{
+		Subjects:   c.Subjects,
+		Action:     c.Action,
+		Object:     c.Object,
+		Strict:     strict,
+		Attributes: c.Attributes,
+		Principal:  c.Principal,
+		Resource:   c.Resource,
+	})
+	if err != nil {
+		r.Trace.Err = err
+		r.Failures = append(r.Failures, fmt.Sprintf("check failed: %v", err))
+		return r
+	}
+
+	r.Trace.Granted = perm.Granted()
+	for _, p := range perm.Grant().Policies() {
+		r.Trace.Matched = append(r.Trace.Matched, p.Key())
+	}
+	sort.Strings(r.Trace.Matched)
+
+	if got := decision(perm.Granted()); got != c.Expect {
+		r.Failures = append(r.Failures, fmt.Sprintf("expected %s, got %s", c.Expect, got))
+	}
+
+	if c.Data == nil {
+		if c.Visible != nil || c.Writable != nil {
+			r.Failures = append(r.Failures, "visible and writable expectations require data")
+		}
+		return r
+	}
+	if !perm.Granted() {
+		return r
+	}
+
+	visible, err := perm.Filter(c.Data)
+	if err != nil {
+		r.Failures = append(r.Failures, fmt.Sprintf("filter failed: %v", err))
+		return r
+	}
+	r.Trace.Visible = paths(visible, "")
+
+	writable, err := perm.Field(c.Data)
+	if err != nil {
+		r.Failures = append(r.Failures, fmt.Sprintf("field failed: %v", err))
+		return r
+	}
+	r.Trace.Writable = paths(writable, "")
+
+	if c.Visible != nil && !samePaths(c.Visible, r.Trace.Visible) {
+		r.Failures = append(r.Failures, fmt.Sprintf("expected visible %v, got %v", sorted(c.Visible), r.Trace.Visible))
+	}
+	if c.Writable != nil && !samePaths(c.Writable, r.Trace.Writable) {
+		r.Failures = append(r.Failures, fmt.Sprintf("expected writable %v, got %v", sorted(c.Writable), r.Trace.Writable))
+	}
+
+	return r
+}
+
+// Write prints a human readable report, verbose also prints passing cases and their traces
+func (r *Result) Write(w io.Writer, verbose bool) {
+	for _, c := range r.Cases {
+		if c.Passed() {
+			if verbose {
+				fmt.Fprintf(w, "PASS %s\n", c.Case.Name)
+				writeTrace(w, c.Trace)
+			}
+			continue
+		}
+
+		fmt.Fprintf(w, "FAIL %s\n", c.Case.Name)
+		for _, f := range c.Failures {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+		writeTrace(w, c.Trace)
+	}
+
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(r.Cases)-r.Failed(), r.Failed())
+	fmt.Fprintf(w, "coverage: %d/%d policies exercised\n", r.Coverage.Total-len(r.Coverage.Unused), r.Coverage.Total)
+	for _, key := range r.Coverage.Unused {
+		fmt.Fprintf(w, "    unused: %s\n", key)
+	}
+}
+
+func writeTrace(w io.Writer, t Trace) {
+	fmt.Fprintf(w, "    trace: %s, matched [%s]\n", decision(t.Granted), strings.Join(t.Matched, ", "))
+	if t.Visible != nil {
+		fmt.Fprintf(w, "    visible: %v\n", t.Visible)
+	}
+	if t.Writable != nil {
+		fmt.Fprintf(w, "    writable: %v\n", t.Writable)
+	}
+}
+
+func decision(granted bool) string {
+	if granted {
+		return "allow"
+	}
+	return "deny"
+}
+
+// paths flattens a document into its sorted leaf paths in dot notation
+func paths(doc map[string]any, prefix string) []string {
+	out := []string{}
+	for k, v := range doc {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			out = append(out, paths(nested, path)...)
+			continue
+		}
+		out = append(out, path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func samePaths(expected, got []string) bool {
+	return reflect.DeepEqual(sorted(expected), got)
+}
+
+func sorted(s []string) []string {
+	out := append([]string{}, s...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,202 @@
+package policytest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/alipourhabibi/abacl-go/rebac"
+	rebacmem "github.com/alipourhabibi/abacl-go/rebac/memory"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a declarative set of policies and the checks they must satisfy
+// Suites are written in YAML or JSON
+type Suite struct {
+	Options  Options  `yaml:"options" json:"options"`
+	Policies []Policy `yaml:"policies" json:"policies"`
+	Cases    []Case   `yaml:"cases" json:"cases"`
+
+	// Tuples are the relationships evaluated for policies with a relation,
+	// written object#relation@subject
+	Tuples []string `yaml:"tuples" json:"tuples"`
+}
+
+// Options mirrors acl.Options
+type Options struct {
+	Strict bool   `yaml:"strict" json:"strict"`
+	Merge  string `yaml:"merge" json:"merge"` // union, intersection or most-restrictive
+}
+
+// Policy is the declarative form of policy.Policy
+type Policy struct {
+	Tenant     string      `yaml:"tenant" json:"tenant"`
+	Subject    string      `yaml:"subject" json:"subject"`
+	Action     string      `yaml:"action" json:"action"`
+	Object     string      `yaml:"object" json:"object"`
+	Cascade    bool        `yaml:"cascade" json:"cascade"`
+	Relation   string      `yaml:"relation" json:"relation"`
+	Fields     []string    `yaml:"fields" json:"fields"`
+	Filters    []string    `yaml:"filters" json:"filters"`
+	Conditions []Condition `yaml:"conditions" json:"conditions"`
+
+	TimeWindows []TimeWindow `yaml:"timeWindows" json:"timeWindows"`
+	Locations   []string     `yaml:"locations" json:"locations"`
+	Obligations []Obligation `yaml:"obligations" json:"obligations"`
+}
+
+// TimeWindow is the declarative form of policy.TimeWindow, durations are written like "8h"
+type TimeWindow struct {
+	Cron     string        `yaml:"cron" json:"cron"`
+	Duration time.Duration `yaml:"duration" json:"duration"`
+}
+
+// Obligation is the declarative form of policy.Obligation
+type Obligation struct {
+	Type   string         `yaml:"type" json:"type"`
+	Params map[string]any `yaml:"params" json:"params"`
+	Advice bool           `yaml:"advice" json:"advice"`
+}
+
+// Condition is the declarative form of policy.Condition
+type Condition struct {
+	Field string `yaml:"field" json:"field"`
+	Op    string `yaml:"op" json:"op"`
+	Value any    `yaml:"value" json:"value"`
+}
+
+// Case is a single check and its expected outcome
+type Case struct {
+	Name     string   `yaml:"name" json:"name"`
+	Tenant   string   `yaml:"tenant" json:"tenant"`
+	Subjects []string `yaml:"subjects" json:"subjects"`
+	Action   string   `yaml:"action" json:"action"`
+	Object   string   `yaml:"object" json:"object"`
+	Strict   *bool    `yaml:"strict" json:"strict"` // Defaults to the suite options
+
+	// Attributes of the subject, compared to the parameters of object paths
+	Attributes map[string]any `yaml:"attributes" json:"attributes"`
+
+	// Principal and Resource are checked against the suite's tuples for
+	// policies with a relation, e.g. "user:alice" and "document:9"
+	Principal string `yaml:"principal" json:"principal"`
+	Resource  string `yaml:"resource" json:"resource"`
+
+	// Expect is "allow" or "deny"
+	Expect string `yaml:"expect" json:"expect"`
+
+	// Data is a sample document for the visible and writable expectations
+	Data map[string]any `yaml:"data" json:"data"`
+
+	// Visible lists the field paths of Data left by Filter, in dot notation
+	Visible []string `yaml:"visible" json:"visible"`
+
+	// Writable lists the field paths of Data left by Field, in dot notation
+	Writable []string `yaml:"writable" json:"writable"`
+}
+
+// Parse reads a suite in YAML or JSON, unknown keys are rejected
+func Parse(data []byte) (*Suite, error) {
+	var s Suite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid suite: %w", err)
+	}
+
+	for i, c := range s.Cases {
+		if c.Expect != "allow" && c.Expect != "deny" {
+			return nil, fmt.Errorf("case %d (%s): expect must be allow or deny", i, c.Name)
+		}
+	}
+	return &s, nil
+}
+
+// Load reads a suite from a YAML or JSON file
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// AccessControl creates an in-memory access control holding the suite's policies
+func (s *Suite) AccessControl() (*acl.AccessControl, error) {
+	opts := acl.Options{Strict: s.Options.Strict}
+	switch s.Options.Merge {
+	case "", "union":
+		opts.Merge = grant.MergeUnion
+	case "intersection":
+		opts.Merge = grant.MergeIntersection
+	case "most-restrictive":
+		opts.Merge = grant.MergeMostRestrictive
+	default:
+		return nil, fmt.Errorf("unknown merge mode %q", s.Options.Merge)
+	}
+
+	policies := make([]policy.Policy, 0, len(s.Policies))
+	for _, p := range s.Policies {
+		policies = append(policies, p.ToPolicy())
+	}
+
+	if len(s.Tuples) > 0 {
+		store := rebacmem.NewMemoryStore()
+		for _, spec := range s.Tuples {
+			t, err := rebac.ParseTuple(spec)
+			if err != nil {
+				return nil, err
+			}
+			if err := store.Write(t); err != nil {
+				return nil, err
+			}
+		}
+		opts.Relations = rebac.New(store, nil)
+	}
+
+	return acl.New(policies, opts, memory.NewMemoryDriver())
+}
+
+// ToPolicy converts the declarative policy to a policy.Policy
+func (p Policy) ToPolicy() policy.Policy {
+	var conditions []policy.Condition
+	for _, c := range p.Conditions {
+		conditions = append(conditions, policy.Condition{
+			Field: c.Field,
+			Op:    policy.Operator(c.Op),
+			Value: c.Value,
+		})
+	}
+
+	var windows []policy.TimeWindow
+	for _, w := range p.TimeWindows {
+		windows = append(windows, policy.TimeWindow{CronExpr: w.Cron, Duration: w.Duration})
+	}
+
+	var obligations []policy.Obligation
+	for _, o := range p.Obligations {
+		obligations = append(obligations, policy.Obligation{Type: o.Type, Params: o.Params, Advice: o.Advice})
+	}
+
+	return policy.Policy{
+		Tenant:      p.Tenant,
+		Subject:     p.Subject,
+		Action:      p.Action,
+		Object:      p.Object,
+		Cascade:     p.Cascade,
+		Relation:    p.Relation,
+		TimeWindows: windows,
+		Fields:      p.Fields,
+		Filters:     p.Filters,
+		Conditions:  conditions,
+		Locations:   p.Locations,
+		Obligations: obligations,
+	}
+}
@@ -0,0 +1,294 @@
+package protomask
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Options configures how globs name the fields of messages
+type Options struct {
+	// UseProtoNames matches globs against proto field names, "user_id", instead
+	// of the JSON names protojson writes, "userId"
+	UseProtoNames bool
+}
+
+// Field returns a copy of msg keeping only the fields the grant's Fields allow,
+// the way Field filters the JSON form of msg
+// A denied permission returns an empty message
+func Field[T proto.Message](perm *permission.Permission, msg T, opts Options) (T, error) {
+	return apply(msg, opts, perm.Field)
+}
+
+// Filter returns a copy of msg keeping only the fields the grant's Filters show
+// A denied permission returns an empty message
+//
+// Nested messages, repeated messages and maps use the notation of the globs:
+// "author.email", "comments[].author" and "labels.env"; oneof fields are named
+// directly, like in JSON
+func Filter[T proto.Message](perm *permission.Permission, msg T, opts Options) (T, error) {
+	return apply(msg, opts, perm.Filter)
+}
+
+func apply[T proto.Message](msg T, opts Options, filter func(any) (map[string]any, error)) (T, error) {
+	m := msg.ProtoReflect()
+	if !m.IsValid() {
+		return msg, nil
+	}
+
+	allowed, err := filter(document(m, opts))
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	out := proto.Clone(msg)
+	if allowed == nil {
+		out = m.Type().New().Interface()
+	} else {
+		mask(out.ProtoReflect(), allowed, opts)
+	}
+	return out.(T), nil
+}
+
+// Mask errors, an empty mask is never returned since many APIs read it as "all fields"
+var (
+	ErrDenied   = errors.New("permission denied")
+	ErrNoFields = errors.New("no field is allowed")
+)
+
+// FieldMask returns the field mask of the fields of desc the grant's Fields allow
+// Paths use proto names as FieldMask requires. Fields a mask cannot express
+// partially, subfields of repeated fields and keys of maps, are left out
+// A denied permission returns ErrDenied, a mask without paths ErrNoFields
+func FieldMask(perm *permission.Permission, desc protoreflect.MessageDescriptor, opts Options) (*fieldmaskpb.FieldMask, error) {
+	return fieldMask(perm, desc, opts, perm.Field, func(p policy.Policy) []string { return p.Fields })
+}
+
+// FilterMask is like FieldMask for the grant's Filters
+func FilterMask(perm *permission.Permission, desc protoreflect.MessageDescriptor, opts Options) (*fieldmaskpb.FieldMask, error) {
+	return fieldMask(perm, desc, opts, perm.Filter, func(p policy.Policy) []string { return p.Filters })
+}
+
+func fieldMask(perm *permission.Permission, desc protoreflect.MessageDescriptor, opts Options,
+	filter func(any) (map[string]any, error), globsOf func(policy.Policy) []string) (*fieldmaskpb.FieldMask, error) {
+	if !perm.Granted() {
+		return nil, ErrDenied
+	}
+
+	// Globs reach as deep as their longest path, the type is probed that deep
+	var globs []string
+	depth := 1
+	if g := perm.Grant(); g != nil {
+		for _, p := range g.Policies() {
+			for _, glob := range globsOf(p) {
+				glob = strings.TrimPrefix(glob, "!")
+				globs = append(globs, glob)
+				depth = max(depth, strings.Count(glob, ".")+1)
+			}
+		}
+	}
+
+	full := probe(desc, opts, depth)
+	allowed, err := filter(probe(desc, opts, depth))
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed == nil {
+		return nil, ErrDenied
+	}
+
+	m := masker{opts: opts, globs: globs}
+	fm := &fieldmaskpb.FieldMask{Paths: m.paths(desc, allowed, full, "", "")}
+	if len(fm.Paths) == 0 {
+		return nil, ErrNoFields
+	}
+	return fm, nil
+}
+
+func name(fd protoreflect.FieldDescriptor, opts Options) string {
+	if opts.UseProtoNames {
+		return string(fd.Name())
+	}
+	return fd.JSONName()
+}
+
+// document converts the populated fields of a message to a document for the
+// globs, scalar values are replaced by true
+func document(m protoreflect.Message, opts Options) map[string]any {
+	doc := map[string]any{}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		doc[name(fd, opts)] = value(fd, v, opts)
+		return true
+	})
+	return doc
+}
+
+func value(fd protoreflect.FieldDescriptor, v protoreflect.Value, opts Options) any {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]any, list.Len())
+		for i := range items {
+			items[i] = true
+			if fd.Message() != nil {
+				items[i] = document(list.Get(i).Message(), opts)
+			}
+		}
+		return items
+
+	case fd.IsMap():
+		entries := map[string]any{}
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			entries[k.String()] = true
+			if fd.MapValue().Message() != nil {
+				entries[k.String()] = document(mv.Message(), opts)
+			}
+			return true
+		})
+		return entries
+
+	case fd.Message() != nil:
+		return document(v.Message(), opts)
+	}
+	return true
+}
+
+// mask clears the fields of m missing from allowed, the filtered document of m
+func mask(m protoreflect.Message, allowed map[string]any, opts Options) {
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		a, ok := allowed[name(fd, opts)]
+		if !ok {
+			m.Clear(fd)
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			if fd.Message() == nil {
+				continue
+			}
+			items, _ := a.([]any)
+			list := m.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				var item map[string]any
+				if i < len(items) {
+					item, _ = items[i].(map[string]any)
+				}
+				mask(list.Get(i).Message(), item, opts)
+			}
+
+		case fd.IsMap():
+			entries, _ := a.(map[string]any)
+			mp := m.Mutable(fd).Map()
+			var removed []protoreflect.MapKey
+			mp.Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entry, ok := entries[k.String()]
+				switch {
+				case !ok:
+					removed = append(removed, k)
+				case fd.MapValue().Message() != nil:
+					sub, _ := entry.(map[string]any)
+					mask(mv.Message(), sub, opts)
+				}
+				return true
+			})
+			for _, k := range removed {
+				mp.Clear(k)
+			}
+
+		case fd.Message() != nil:
+			sub, _ := a.(map[string]any)
+			mask(m.Mutable(fd).Message(), sub, opts)
+		}
+	}
+}
+
+// probe builds a document of every field of a message type down to depth,
+// deeper messages and maps are leaves
+func probe(desc protoreflect.MessageDescriptor, opts Options, depth int) map[string]any {
+	doc := map[string]any{}
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		var v any = true
+		if fd.Message() != nil && !fd.IsMap() && depth > 1 {
+			v = probe(fd.Message(), opts, depth-1)
+		}
+		if fd.IsList() {
+			v = []any{v}
+		}
+		doc[name(fd, opts)] = v
+	}
+	return doc
+}
+
+// masker lists the paths of a field mask
+type masker struct {
+	opts  Options
+	globs []string // Glob paths without negation
+}
+
+// paths lists the proto paths of the fields allowed whole, descending into
+// the messages allowed in part; globPrefix is the path in glob notation
+func (m masker) paths(desc protoreflect.MessageDescriptor, allowed, full map[string]any, prefix, globPrefix string) []string {
+	var out []string
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		key := name(fd, m.opts)
+		a, ok := allowed[key]
+		if !ok {
+			continue
+		}
+
+		path := prefix + string(fd.Name())
+		globPath := globPrefix + key
+		if fd.IsList() {
+			globPath += "[]"
+		}
+
+		f := full[key]
+		if reflect.DeepEqual(a, f) {
+			// Leaves stand for whole maps and messages, unless a glob looks inside
+			if !m.inside(globPath) && !m.inside(globPrefix+key) {
+				out = append(out, path)
+			}
+			continue
+		}
+
+		// A mask cannot select the fields of repeated elements
+		if fd.IsList() || fd.IsMap() {
+			continue
+		}
+		sub, ok := a.(map[string]any)
+		subFull, okFull := f.(map[string]any)
+		if ok && okFull && fd.Message() != nil {
+			out = append(out, m.paths(fd.Message(), sub, subFull, path+".", globPath+".")...)
+		}
+	}
+	return out
+}
+
+// inside reports whether a glob selects part of the field at globPath
+func (m masker) inside(globPath string) bool {
+	for _, g := range m.globs {
+		if strings.HasPrefix(g, globPath+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package protomask
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func articleType(t *testing.T) protoreflect.MessageDescriptor {
+	data, err := os.ReadFile("testdata/article.textproto")
+	require.NoError(t, err)
+	fdp := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, prototext.Unmarshal(data, fdp))
+	fd, err := protodesc.NewFile(fdp, nil)
+	require.NoError(t, err)
+	return fd.Messages().ByName("Article")
+}
+
+func article(t *testing.T, desc protoreflect.MessageDescriptor) proto.Message {
+	msg := dynamicpb.NewMessage(desc)
+	require.NoError(t, protojson.Unmarshal([]byte(`{
+		"id": "1",
+		"title": "Hello",
+		"secretNote": "draft",
+		"author": {"name": "Alice", "email": "alice@example.com"},
+		"comments": [{"body": "Nice", "author": "bob"}, {"body": "Thanks", "author": "alice"}],
+		"labels": {"env": "prod", "team": "blog"},
+		"reviewers": {"r1": {"name": "Carol", "email": "carol@example.com"}},
+		"text": "Body",
+		"tags": ["go", "acl"],
+		"related": {"id": "2", "title": "Related", "secretNote": "x"}
+	}`), msg))
+	return msg
+}
+
+func check(t *testing.T, subject string) *permission.Permission {
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article",
+			Filters: []string{"*", "!secretNote", "!author.email", "!comments[].author", "!labels.team", "!related.secretNote"}},
+		{Subject: "guest", Action: "read", Object: "article", Fields: []string{"title", "text"},
+			Filters: []string{"id", "title", "author.name", "comments[].body", "reviewers"}},
+		{Subject: "proto", Action: "read", Object: "article", Filters: []string{"*", "!secret_note"}},
+		{Subject: "hidden", Action: "read", Object: "article", Filters: []string{"unknown"}},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	perm, err := ac.Check([]string{subject}, "read", "article")
+	require.NoError(t, err)
+	return perm
+}
+
+func toJSON(t *testing.T, m proto.Message) string {
+	b, err := protojson.Marshal(m)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestFilter(t *testing.T) {
+	desc := articleType(t)
+
+	for _, tc := range []struct {
+		subject string
+		opts    Options
+		want    string
+	}{
+		{"user", Options{}, `{
+			"id": "1", "title": "Hello",
+			"author": {"name": "Alice"},
+			"comments": [{"body": "Nice"}, {"body": "Thanks"}],
+			"labels": {"env": "prod"},
+			"reviewers": {"r1": {"name": "Carol", "email": "carol@example.com"}},
+			"text": "Body", "tags": ["go", "acl"],
+			"related": {"id": "2", "title": "Related"}
+		}`},
+		{"guest", Options{}, `{
+			"id": "1", "title": "Hello",
+			"author": {"name": "Alice"},
+			"comments": [{"body": "Nice"}, {"body": "Thanks"}],
+			"reviewers": {"r1": {"name": "Carol", "email": "carol@example.com"}}
+		}`},
+		{"proto", Options{UseProtoNames: true}, `{
+			"id": "1", "title": "Hello",
+			"author": {"name": "Alice", "email": "alice@example.com"},
+			"comments": [{"body": "Nice", "author": "bob"}, {"body": "Thanks", "author": "alice"}],
+			"labels": {"env": "prod", "team": "blog"},
+			"reviewers": {"r1": {"name": "Carol", "email": "carol@example.com"}},
+			"text": "Body", "tags": ["go", "acl"],
+			"related": {"id": "2", "title": "Related", "secretNote": "x"}
+		}`},
+		{"nobody", Options{}, `{}`},
+	} {
+		t.Run(tc.subject, func(t *testing.T) {
+			msg := article(t, desc)
+			before := toJSON(t, msg)
+
+			masked, err := Filter(check(t, tc.subject), msg, tc.opts)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, toJSON(t, masked))
+			assert.Equal(t, before, toJSON(t, msg), "the original message is not modified")
+		})
+	}
+}
+
+func TestFilter_MatchesJSON(t *testing.T) {
+	desc := articleType(t)
+	msg := article(t, desc)
+
+	var doc map[string]any
+	require.NoError(t, protojsonDoc(msg, &doc))
+
+	for _, subject := range []string{"user", "guest"} {
+		perm := check(t, subject)
+		filtered, err := perm.Filter(doc)
+		require.NoError(t, err)
+
+		masked, err := Filter(perm, msg, Options{})
+		require.NoError(t, err)
+		var got map[string]any
+		require.NoError(t, protojsonDoc(masked, &got))
+		assert.Equal(t, filtered, got, subject)
+	}
+}
+
+func protojsonDoc(m proto.Message, doc *map[string]any) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	*doc = nil
+	return json.Unmarshal(b, doc)
+}
+
+func TestField(t *testing.T) {
+	desc := articleType(t)
+	masked, err := Field(check(t, "guest"), article(t, desc), Options{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Hello", "text": "Body"}`, toJSON(t, masked))
+
+	// Only the set field of a oneof is kept or cleared
+	msg := article(t, desc)
+	msg.ProtoReflect().Set(desc.Fields().ByName("pdf"), protoreflect.ValueOfBytes([]byte("%PDF")))
+	masked, err = Field(check(t, "guest"), msg, Options{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Hello"}`, toJSON(t, masked))
+}
+
+func TestFilterMask(t *testing.T) {
+	desc := articleType(t)
+
+	for _, tc := range []struct {
+		subject string
+		opts    Options
+		want    []string
+	}{
+		// comments.body and labels.env cannot be expressed, comments and labels are left out
+		{"user", Options{}, []string{"id", "title", "author.name", "reviewers", "text", "pdf", "tags",
+			"related.id", "related.title", "related.author", "related.comments", "related.labels", "related.reviewers",
+			"related.text", "related.pdf", "related.tags", "related.related"}},
+		{"guest", Options{}, []string{"id", "title", "author.name", "reviewers"}},
+		{"proto", Options{UseProtoNames: true}, []string{"id", "title", "author", "comments", "labels", "reviewers", "text", "pdf", "tags", "related"}},
+	} {
+		t.Run(tc.subject, func(t *testing.T) {
+			fm, err := FilterMask(check(t, tc.subject), desc, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, fm.GetPaths())
+			assert.True(t, fm.IsValid(dynamicpb.NewMessage(desc)))
+		})
+	}
+
+	fm, err := FieldMask(check(t, "guest"), desc, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "text"}, fm.GetPaths())
+
+	// An empty mask would read as "all fields"
+	fm, err = FilterMask(check(t, "nobody"), desc, Options{})
+	assert.ErrorIs(t, err, ErrDenied)
+	assert.Nil(t, fm)
+
+	fm, err = FilterMask(check(t, "hidden"), desc, Options{})
+	assert.ErrorIs(t, err, ErrNoFields)
+	assert.Nil(t, fm)
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/alipourhabibi/abacl-go/rebac"
+)
+
+// MemoryStore provides an in-memory implementation of the rebac.Store interface
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tuples map[string]map[string]struct{} // object#relation to subjects
+}
+
+// NewMemoryStore creates a new in-memory tuple store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tuples: make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MemoryStore) Write(tuples ...rebac.Tuple) error {
+	for _, t := range tuples {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("invalid tuple: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tuples {
+		node := t.Object + "#" + t.Relation
+		if m.tuples[node] == nil {
+			m.tuples[node] = make(map[string]struct{})
+		}
+		m.tuples[node][t.Subject] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryStore) Delete(tuples ...rebac.Tuple) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tuples {
+		node := t.Object + "#" + t.Relation
+		delete(m.tuples[node], t.Subject)
+		if len(m.tuples[node]) == 0 {
+			delete(m.tuples, node)
+		}
+	}
+	return nil
+}
+
+// Read returns the tuples sorted by subject
+func (m *MemoryStore) Read(object, relation string) ([]rebac.Tuple, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subjects := m.tuples[object+"#"+relation]
+	tuples := make([]rebac.Tuple, 0, len(subjects))
+	for s := range subjects {
+		tuples = append(tuples, rebac.Tuple{Object: object, Relation: relation, Subject: s})
+	}
+
+	sort.Slice(tuples, func(i, j int) bool { return tuples[i].Subject < tuples[j].Subject })
+	return tuples, nil
+}
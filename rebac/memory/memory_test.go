@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/rebac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	m := NewMemoryStore()
+	bob := rebac.Tuple{Object: "document:9", Relation: "viewer", Subject: "user:bob"}
+	alice := rebac.Tuple{Object: "document:9", Relation: "viewer", Subject: "user:alice"}
+	eng := rebac.Tuple{Object: "document:9", Relation: "viewer", Subject: "group:eng#member"}
+
+	require.NoError(t, m.Write(bob, alice, eng))
+	require.NoError(t, m.Write(bob)) // Writing an existing tuple is a no-op
+
+	tuples, err := m.Read("document:9", "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, []rebac.Tuple{eng, alice, bob}, tuples)
+
+	tuples, err = m.Read("document:9", "editor")
+	require.NoError(t, err)
+	assert.Empty(t, tuples)
+
+	require.NoError(t, m.Delete(alice, rebac.Tuple{Object: "document:1", Relation: "viewer", Subject: "user:alice"}))
+	tuples, err = m.Read("document:9", "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, []rebac.Tuple{eng, bob}, tuples)
+
+	require.NoError(t, m.Delete(eng, bob))
+	assert.Empty(t, m.tuples, "empty relations are dropped")
+}
+
+func TestMemoryStore_Invalid(t *testing.T) {
+	m := NewMemoryStore()
+	valid := rebac.Tuple{Object: "document:9", Relation: "viewer", Subject: "user:bob"}
+	err := m.Write(valid, rebac.Tuple{Object: "document", Relation: "viewer", Subject: "user:bob"})
+	assert.ErrorContains(t, err, "invalid tuple")
+
+	// Nothing is written when a tuple is invalid
+	tuples, err := m.Read("document:9", "viewer")
+	require.NoError(t, err)
+	assert.Empty(t, tuples)
+}
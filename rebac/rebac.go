@@ -0,0 +1,208 @@
+package rebac
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxDepth bounds the number of nested relations a check follows
+const MaxDepth = 32
+
+var (
+	objectRef = regexp.MustCompile(`^[A-Za-z0-9_.-]+:[^#@\s]+$`)
+	relation  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Tuple states that a subject holds a relation on an object: document:9#viewer@user:alice
+// Objects are written "type:id", subjects are objects or usersets "type:id#relation",
+// e.g. document:9#viewer@folder:3#viewer makes every viewer of the folder a viewer of the document
+type Tuple struct {
+	Object   string
+	Relation string
+	Subject  string
+}
+
+// ParseTuple reads a tuple written as object#relation@subject
+func ParseTuple(s string) (Tuple, error) {
+	obj, rest, ok := strings.Cut(s, "#")
+	if !ok {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: missing '#'", s)
+	}
+	rel, subj, ok := strings.Cut(rest, "@")
+	if !ok {
+		return Tuple{}, fmt.Errorf("invalid tuple %q: missing '@'", s)
+	}
+
+	t := Tuple{Object: obj, Relation: rel, Subject: subj}
+	return t, t.Validate()
+}
+
+func (t Tuple) String() string {
+	return t.Object + "#" + t.Relation + "@" + t.Subject
+}
+
+// Validate checks if the tuple is well-formed
+func (t Tuple) Validate() error {
+	if !objectRef.MatchString(t.Object) {
+		return fmt.Errorf("tuple object %q must be written type:id", t.Object)
+	}
+	if !relation.MatchString(t.Relation) {
+		return fmt.Errorf("tuple relation %q must be an identifier", t.Relation)
+	}
+
+	obj, rel, userset := strings.Cut(t.Subject, "#")
+	if !objectRef.MatchString(obj) {
+		return fmt.Errorf("tuple subject %q must be written type:id or type:id#relation", t.Subject)
+	}
+	if userset && !relation.MatchString(rel) {
+		return fmt.Errorf("tuple subject relation %q must be an identifier", rel)
+	}
+	return nil
+}
+
+// Store defines the storage interface for relationship tuples
+type Store interface {
+	// Write stores tuples, writing an existing tuple is a no-op
+	Write(tuples ...Tuple) error
+
+	// Delete removes tuples, deleting a missing tuple is a no-op
+	Delete(tuples ...Tuple) error
+
+	// Read returns the tuples of a relation on an object
+	Read(object, relation string) ([]Tuple, error)
+}
+
+// Rewrite defines a relation from other relations, Zanzibar's userset rewrites
+// A subject holds the relation if it holds any of them
+type Rewrite struct {
+	// Computed relations on the same object imply this one: editors are also viewers
+	Computed []string
+
+	// Parents follow a relation to other objects and check a relation there:
+	// viewers of a document's parent folder are viewers of the document
+	Parents []Parent
+}
+
+// Parent is a tuple-to-userset rewrite
+type Parent struct {
+	Relation string // Relation linking the object to its parents, e.g. "parent"
+	Computed string // Relation checked on the parents, e.g. "viewer"
+}
+
+// Schema lists the rewrites of the relations of each object type
+// Relations without a rewrite only hold through their stored tuples
+type Schema map[string]map[string]Rewrite
+
+// Checker evaluates relationship checks against a store
+type Checker struct {
+	store  Store
+	schema Schema
+}
+
+// New creates a new Checker
+func New(store Store, schema Schema) *Checker {
+	return &Checker{store: store, schema: schema}
+}
+
+// Check reports whether subject holds relation on object
+// The subject is an object like "user:alice" or a userset like "group:eng#member"
+// Each relation is evaluated once per check, however many paths reach it
+func (c *Checker) Check(object, relation, subject string) (bool, error) {
+	w := &walk{subject: subject, visiting: map[string]bool{}, memo: map[string]bool{}}
+	ok, _, err := c.check(w, object, relation, 0)
+	return ok, err
+}
+
+// walk holds the state of a single check
+type walk struct {
+	subject  string
+	visiting map[string]bool // Relations being evaluated, by object#relation
+	memo     map[string]bool // Results of the evaluated relations
+}
+
+// check reports whether the subject holds rel on object, and whether the result is
+// complete: a false reached by cutting a cycle may still turn true through the
+// relation being evaluated, so it is not memoized
+func (c *Checker) check(w *walk, object, rel string, depth int) (bool, bool, error) {
+	node := object + "#" + rel
+	if ok, seen := w.memo[node]; seen {
+		return ok, true, nil
+	}
+	if depth > MaxDepth {
+		return false, false, fmt.Errorf("relation %s nests deeper than %d", node, MaxDepth)
+	}
+
+	// A relation reached again through itself adds nothing
+	if w.visiting[node] {
+		return false, false, nil
+	}
+	w.visiting[node] = true
+	defer delete(w.visiting, node)
+
+	ok, complete, err := c.expand(w, object, rel, node, depth)
+	if err == nil && (ok || complete) {
+		w.memo[node] = ok
+	}
+	return ok, complete, err
+}
+
+// expand evaluates the tuples and rewrites of a relation
+func (c *Checker) expand(w *walk, object, rel, node string, depth int) (bool, bool, error) {
+	if node == w.subject {
+		return true, true, nil
+	}
+
+	complete := true
+	sub := func(object, rel string) (bool, error) {
+		ok, done, err := c.check(w, object, rel, depth+1)
+		complete = complete && done
+		return ok, err
+	}
+
+	tuples, err := c.store.Read(object, rel)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to read %s: %w", node, err)
+	}
+	for _, t := range tuples {
+		if t.Subject == w.subject {
+			return true, true, nil
+		}
+		obj, setRel, userset := strings.Cut(t.Subject, "#")
+		if !userset {
+			continue
+		}
+		if ok, err := sub(obj, setRel); ok || err != nil {
+			return ok, true, err
+		}
+	}
+
+	rw, ok := c.rewrite(object, rel)
+	if !ok {
+		return false, complete, nil
+	}
+	for _, computed := range rw.Computed {
+		if ok, err := sub(object, computed); ok || err != nil {
+			return ok, true, err
+		}
+	}
+	for _, parent := range rw.Parents {
+		parents, err := c.store.Read(object, parent.Relation)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to read %s#%s: %w", object, parent.Relation, err)
+		}
+		for _, t := range parents {
+			obj, _, _ := strings.Cut(t.Subject, "#")
+			if ok, err := sub(obj, parent.Computed); ok || err != nil {
+				return ok, true, err
+			}
+		}
+	}
+	return false, complete, nil
+}
+
+func (c *Checker) rewrite(object, rel string) (Rewrite, bool) {
+	typ, _, _ := strings.Cut(object, ":")
+	rw, ok := c.schema[typ][rel]
+	return rw, ok
+}
@@ -0,0 +1,148 @@
+package rebac_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/rebac"
+	"github.com/alipourhabibi/abacl-go/rebac/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tuples(t *testing.T, specs ...string) []rebac.Tuple {
+	t.Helper()
+	out := make([]rebac.Tuple, 0, len(specs))
+	for _, s := range specs {
+		tp, err := rebac.ParseTuple(s)
+		require.NoError(t, err)
+		out = append(out, tp)
+	}
+	return out
+}
+
+func TestChecker(t *testing.T) {
+	store := memory.NewMemoryStore()
+	require.NoError(t, store.Write(tuples(t,
+		"folder:3#viewer@user:bob",
+		"folder:3#editor@group:eng#member",
+		"group:eng#member@user:carol",
+		"document:9#parent@folder:3",
+		"document:9#owner@user:alice",
+	)...))
+
+	checker := rebac.New(store, rebac.Schema{
+		"document": {
+			"editor": {Computed: []string{"owner"}},
+			"viewer": {Computed: []string{"editor"}, Parents: []rebac.Parent{{Relation: "parent", Computed: "viewer"}}},
+		},
+		"folder": {
+			"viewer": {Computed: []string{"editor"}},
+		},
+	})
+
+	tests := []struct {
+		object, relation, subject string
+		want                      bool
+	}{
+		{"document:9", "owner", "user:alice", true},
+		{"document:9", "viewer", "user:alice", true}, // owner -> editor -> viewer
+		{"document:9", "viewer", "user:bob", true},   // viewer of the parent folder
+		{"document:9", "viewer", "user:carol", true}, // member of a group editing the folder
+		{"document:9", "editor", "user:bob", false},  // folder viewers are not editors
+		{"document:9", "viewer", "user:dave", false},
+		{"folder:3", "viewer", "group:eng#member", true},
+	}
+	for _, tt := range tests {
+		ok, err := checker.Check(tt.object, tt.relation, tt.subject)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, ok, "%s#%s@%s", tt.object, tt.relation, tt.subject)
+	}
+
+	require.NoError(t, store.Delete(tuples(t, "group:eng#member@user:carol")...))
+	ok, err := checker.Check("document:9", "viewer", "user:carol")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestChecker_Cycle(t *testing.T) {
+	store := memory.NewMemoryStore()
+	require.NoError(t, store.Write(tuples(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+	)...))
+
+	ok, err := rebac.New(store, nil).Check("group:a", "member", "user:alice")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// countingStore counts the reads of a store
+type countingStore struct {
+	rebac.Store
+	reads int
+}
+
+func (s *countingStore) Read(object, relation string) ([]rebac.Tuple, error) {
+	s.reads++
+	return s.Store.Read(object, relation)
+}
+
+func TestChecker_Shared(t *testing.T) {
+	// Every level has two groups holding both groups of the next level, 2^30 paths
+	// reach the last level without memoizing the relations
+	const levels = 30
+	var specs []string
+	for i := 0; i < levels; i++ {
+		for _, g := range []string{"a", "b"} {
+			specs = append(specs,
+				fmt.Sprintf("group:%d%s#member@group:%da#member", i, g, i+1),
+				fmt.Sprintf("group:%d%s#member@group:%db#member", i, g, i+1))
+		}
+	}
+	mem := memory.NewMemoryStore()
+	require.NoError(t, mem.Write(tuples(t, specs...)...))
+	store := &countingStore{Store: mem}
+
+	ok, err := rebac.New(store, nil).Check("group:0a", "member", "user:alice")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.LessOrEqual(t, store.reads, 2*(levels+1))
+
+	// A nested member is still found
+	require.NoError(t, mem.Write(tuples(t, fmt.Sprintf("group:%db#member@user:alice", levels))...))
+	ok, err = rebac.New(store, nil).Check("group:0a", "member", "user:alice")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestChecker_CycleNotMemoized(t *testing.T) {
+	// b is cut while a is evaluated, its false must not hide c's member from d
+	store := memory.NewMemoryStore()
+	require.NoError(t, store.Write(tuples(t,
+		"group:a#member@group:b#member",
+		"group:a#member@group:d#member",
+		"group:b#member@group:a#member",
+		"group:d#member@group:b#member",
+		"group:d#member@group:c#member",
+		"group:c#member@user:alice",
+	)...))
+
+	for _, g := range []string{"group:a", "group:b", "group:d"} {
+		ok, err := rebac.New(store, nil).Check(g, "member", "user:alice")
+		require.NoError(t, err)
+		assert.True(t, ok, g)
+	}
+}
+
+func TestParseTuple(t *testing.T) {
+	tp, err := rebac.ParseTuple("document:9#viewer@folder:3#viewer")
+	require.NoError(t, err)
+	assert.Equal(t, rebac.Tuple{Object: "document:9", Relation: "viewer", Subject: "folder:3#viewer"}, tp)
+	assert.Equal(t, "document:9#viewer@folder:3#viewer", tp.String())
+
+	for _, s := range []string{"document:9viewer@user:alice", "document#viewer@user:alice", "document:9#view-er@user:alice", "document:9#viewer@alice"} {
+		_, err := rebac.ParseTuple(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,130 @@
+package simulate
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Op is the kind of a candidate policy change
+type Op string
+
+const (
+	OpAdd    Op = "add"
+	OpRemove Op = "remove"
+)
+
+// Change is a candidate modification of the policies
+type Change struct {
+	Op     Op
+	Policy policy.Policy
+}
+
+// Request is a recorded check to replay against the candidate policies
+type Request struct {
+	acl.Request
+
+	// Data is optional sample data, its Field and Filter outputs are compared too
+	Data any
+}
+
+// Outcome is the result of a request under one policy set
+type Outcome struct {
+	Granted bool
+	Fields  map[string]any // Output of Field on the request data
+	Filters map[string]any // Output of Filter on the request data
+	Err     error
+}
+
+// Difference is a request whose outcome changes with the candidate policies
+type Difference struct {
+	Request Request
+	Before  Outcome
+	After   Outcome
+}
+
+// Report lists the requests affected by the candidate policies
+type Report struct {
+	Checked     int
+	Differences []Difference
+}
+
+// Run replays requests against the current policies of ac and against a copy
+// with changes applied, reporting every decision or output that differs
+// Both sides are evaluated on in-memory copies, ac and its driver are never modified
+func Run(ac *acl.AccessControl, changes []Change, requests []Request) (*Report, error) {
+	current, err := ac.Fork(memory.NewMemoryDriver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy current policies: %w", err)
+	}
+	candidate, err := ac.Fork(memory.NewMemoryDriver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy current policies: %w", err)
+	}
+
+	for _, c := range changes {
+		switch c.Op {
+		case OpAdd:
+			err = candidate.Add(c.Policy)
+		case OpRemove:
+			err = candidate.Remove(c.Policy)
+		default:
+			err = fmt.Errorf("unknown change %q", c.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply change: %w", err)
+		}
+	}
+
+	report := &Report{}
+	for _, req := range requests {
+		before := evaluate(current, req)
+		after := evaluate(candidate, req)
+		report.Checked++
+
+		if !same(before, after) {
+			report.Differences = append(report.Differences, Difference{
+				Request: req,
+				Before:  before,
+				After:   after,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func evaluate(ac *acl.AccessControl, req Request) Outcome {
+	perm, err := ac.CheckRequest(req.Request)
+	if err != nil {
+		return Outcome{Err: err}
+	}
+
+	out := Outcome{Granted: perm.Granted()}
+	if req.Data == nil || !perm.Granted() {
+		return out
+	}
+
+	out.Fields, out.Err = perm.Field(req.Data)
+	if out.Err != nil {
+		return out
+	}
+	out.Filters, out.Err = perm.Filter(req.Data)
+	return out
+}
+
+func same(a, b Outcome) bool {
+	if (a.Err == nil) != (b.Err == nil) {
+		return false
+	}
+	if a.Err != nil {
+		return a.Err.Error() == b.Err.Error()
+	}
+
+	return a.Granted == b.Granted &&
+		reflect.DeepEqual(a.Fields, b.Fields) &&
+		reflect.DeepEqual(a.Filters, b.Filters)
+}
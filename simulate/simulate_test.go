@@ -0,0 +1,93 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/audit"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	logged := 0
+	logger := audit.LoggerFunc(func(audit.Decision) { logged++ })
+
+	read := policy.Policy{Subject: "user", Action: "read", Object: "article", Filters: []string{"*", "!password"}}
+	del := policy.Policy{Subject: "admin", Action: "delete", Object: "article"}
+
+	drv := memory.NewMemoryDriver()
+	ac, err := acl.New([]policy.Policy{read, del}, acl.Options{Strict: true, Logger: logger}, drv)
+	require.NoError(t, err)
+
+	data := map[string]any{"title": "Article", "password": "secret", "notes": "internal"}
+	requests := []Request{
+		{Request: acl.Request{Subjects: []string{"user"}, Action: "read", Object: "article", Strict: true}, Data: data},
+		{Request: acl.Request{Subjects: []string{"admin"}, Action: "delete", Object: "article", Strict: true}},
+		{Request: acl.Request{Subjects: []string{"guest"}, Action: "read", Object: "article", Strict: true}},
+		{Request: acl.Request{Subjects: []string{"user"}, Action: "write", Object: "comment", Strict: true}},
+	}
+
+	changes := []Change{
+		{Op: OpAdd, Policy: policy.Policy{Subject: "user", Action: "read", Object: "article", Filters: []string{"*", "!password", "!notes"}}},
+		{Op: OpRemove, Policy: del},
+		{Op: OpAdd, Policy: policy.Policy{Subject: "guest", Action: "read", Object: "article"}},
+	}
+
+	report, err := Run(ac, changes, requests)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Checked)
+	require.Len(t, report.Differences, 3)
+
+	filters := report.Differences[0]
+	assert.Equal(t, []string{"user"}, filters.Request.Subjects)
+	assert.True(t, filters.Before.Granted)
+	assert.True(t, filters.After.Granted)
+	assert.Contains(t, filters.Before.Filters, "notes")
+	assert.NotContains(t, filters.After.Filters, "notes")
+
+	revoked := report.Differences[1]
+	assert.Equal(t, []string{"admin"}, revoked.Request.Subjects)
+	assert.True(t, revoked.Before.Granted)
+	assert.False(t, revoked.After.Granted)
+
+	granted := report.Differences[2]
+	assert.Equal(t, []string{"guest"}, granted.Request.Subjects)
+	assert.False(t, granted.Before.Granted)
+	assert.True(t, granted.After.Granted)
+
+	// The live access control is untouched and logged nothing
+	assert.True(t, ac.Exists(del))
+	assert.False(t, ac.Exists(policy.Policy{Subject: "guest", Action: "read", Object: "article"}))
+	assert.Zero(t, logged)
+	revs, err := ac.Revisions()
+	require.NoError(t, err)
+	assert.Len(t, revs, 2)
+}
+
+func TestRun_Tenant(t *testing.T) {
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "help"},
+		{Tenant: "acme", Subject: "user", Action: "read", Object: "article"},
+	}, acl.Options{}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	acme := ac.Tenant("acme")
+	requests := []Request{
+		{Request: acl.Request{Subjects: []string{"user"}, Action: "read", Object: "help"}},
+		{Request: acl.Request{Subjects: []string{"user"}, Action: "read", Object: "article"}},
+	}
+
+	// Removing the tenant's policy only affects the tenant request
+	report, err := Run(acme, []Change{
+		{Op: OpRemove, Policy: policy.Policy{Subject: "user", Action: "read", Object: "article"}},
+	}, requests)
+	require.NoError(t, err)
+	require.Len(t, report.Differences, 1)
+	assert.Equal(t, "article", report.Differences[0].Request.Object)
+
+	_, err = Run(acme, []Change{{Op: "rename"}}, requests)
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package sqlfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Options configures how predicates are rendered
+type Options struct {
+	// Placeholder formats the n-th (1-based) bind parameter
+	// Defaults to "?" as used by SQLite and MySQL, use Dollar for PostgreSQL
+	Placeholder func(n int) string
+
+	// Columns maps condition fields to column names
+	// Unmapped fields are used as column names directly
+	Columns map[string]string
+}
+
+// Builder translates grant row conditions into SQL WHERE predicates
+type Builder struct {
+	opts Options
+}
+
+// Question renders "?" placeholders
+func Question(int) string {
+	return "?"
+}
+
+// Dollar renders "$1", "$2", ... placeholders
+func Dollar(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// New creates a new Builder
+func New(opts Options) *Builder {
+	if opts.Placeholder == nil {
+		opts.Placeholder = Question
+	}
+	return &Builder{opts: opts}
+}
+
+// Where builds a parameterized predicate selecting the rows permitted by the grant
+// Policies are OR-ed and their conditions AND-ed, which is the same logic as Grant.Rows
+func (b *Builder) Where(g *grant.Grant, attrs map[string]any) (string, []any, error) {
+	var (
+		clauses []string
+		args    []any
+	)
+
+	for _, p := range g.Policies() {
+		if len(p.Conditions) == 0 {
+			// An unconditional policy permits every row
+			return "1 = 1", nil, nil
+		}
+
+		parts := make([]string, 0, len(p.Conditions))
+		for _, c := range p.Conditions {
+			part, partArgs, err := b.condition(c, attrs, len(args))
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, part)
+			args = append(args, partArgs...)
+		}
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(clauses) == 0 {
+		return "1 = 0", nil, nil
+	}
+
+	return strings.Join(clauses, " OR "), args, nil
+}
+
+// condition renders a single condition, offset is the number of arguments already bound
+func (b *Builder) condition(c policy.Condition, attrs map[string]any, offset int) (string, []any, error) {
+	col, err := b.column(c.Field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, err := c.Resolve(attrs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if value == nil {
+		switch c.Op {
+		case policy.OpEq:
+			return col + " IS NULL", nil, nil
+		case policy.OpNe:
+			return col + " IS NOT NULL", nil, nil
+		}
+		return "", nil, fmt.Errorf("condition %s %s requires a value", c.Field, c.Op)
+	}
+
+	switch c.Op {
+	case policy.OpEq, policy.OpNe, policy.OpLt, policy.OpLte, policy.OpGt, policy.OpGte:
+		op := string(c.Op)
+		if c.Op == policy.OpNe {
+			op = "<>"
+		}
+		return fmt.Sprintf("%s %s %s", col, op, b.opts.Placeholder(offset+1)), []any{value}, nil
+	case policy.OpIn:
+		values, ok := policy.Values(value)
+		if !ok {
+			return "", nil, fmt.Errorf("condition %s in requires a list value", c.Field)
+		}
+		if len(values) == 0 {
+			return "1 = 0", nil, nil
+		}
+		holders := make([]string, len(values))
+		for i := range values {
+			holders[i] = b.opts.Placeholder(offset + i + 1)
+		}
+		return fmt.Sprintf("%s IN (%s)", col, strings.Join(holders, ", ")), values, nil
+	}
+
+	return "", nil, fmt.Errorf("condition %s has unknown operator %q", c.Field, c.Op)
+}
+
+// column maps a condition field to a safe column identifier
+func (b *Builder) column(field string) (string, error) {
+	col := field
+	if mapped, ok := b.opts.Columns[field]; ok {
+		col = mapped
+	}
+	if !identifier.MatchString(col) {
+		return "", fmt.Errorf("invalid column name %q", col)
+	}
+	return col, nil
+}
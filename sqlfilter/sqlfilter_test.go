@@ -0,0 +1,53 @@
+package sqlfilter
+
+import (
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Where_Rendering(t *testing.T) {
+	g, err := grant.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+			{Field: "owner", Op: policy.OpEq, Value: "$user"},
+			{Field: "status", Op: policy.OpIn, Value: []string{"draft", "published"}},
+		}},
+	}, false)
+	require.NoError(t, err)
+
+	b := New(Options{
+		Placeholder: Dollar,
+		Columns:     map[string]string{"owner": "a.owner_id"},
+	})
+	where, args, err := b.Where(g, map[string]any{"user": "john"})
+	require.NoError(t, err)
+	assert.Equal(t, "(a.owner_id = $1 AND status IN ($2, $3))", where)
+	assert.Equal(t, []any{"john", "draft", "published"}, args)
+}
+
+func TestBuilder_Where_Errors(t *testing.T) {
+	b := New(Options{})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		g, _ := grant.New([]policy.Policy{
+			{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+				{Field: "owner", Op: policy.OpEq, Value: "$user.id"},
+			}},
+		}, false)
+		_, _, err := b.Where(g, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsafe column", func(t *testing.T) {
+		g, _ := grant.New([]policy.Policy{
+			{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+				{Field: "owner; DROP TABLE articles", Op: policy.OpEq, Value: "john"},
+			}},
+		}, false)
+		_, _, err := b.Where(g, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,171 @@
+// Package sqlitetest checks that the WHERE clauses of sqlfilter select the same
+// rows as Grant.Rows, against SQLite
+//
+// It is a separate module so the SQLite driver stays out of the dependency graph
+// of the library, run it with: cd sqlfilter/sqlitetest && go test ./...
+package sqlitetest
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/alipourhabibi/abacl-go/sqlfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+var articles = []map[string]any{
+	{"id": int64(1), "owner": "john", "status": "published", "tenant": "acme", "views": int64(10)},
+	{"id": int64(2), "owner": "jane", "status": "draft", "tenant": "acme", "views": int64(0)},
+	{"id": int64(3), "owner": "john", "status": "draft", "tenant": "acme", "views": int64(3)},
+	{"id": int64(4), "owner": "bob", "status": "published", "tenant": "globex", "views": int64(99)},
+	{"id": int64(5), "owner": nil, "status": "archived", "tenant": "acme", "views": int64(7)},
+}
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE articles (id INTEGER PRIMARY KEY, owner TEXT, status TEXT, tenant TEXT, views INTEGER)`)
+	require.NoError(t, err)
+
+	for _, a := range articles {
+		_, err := db.Exec(`INSERT INTO articles VALUES (?, ?, ?, ?, ?)`, a["id"], a["owner"], a["status"], a["tenant"], a["views"])
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func queryIDs(t *testing.T, db *sql.DB, where string, args []any) []int64 {
+	rows, err := db.Query("SELECT id FROM articles WHERE "+where+" ORDER BY id", args...)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	return ids
+}
+
+func memoryIDs(t *testing.T, g *grant.Grant, attrs map[string]any) []int64 {
+	rows, err := g.Rows(articles, attrs)
+	require.NoError(t, err)
+
+	ids := []int64{}
+	for _, r := range rows {
+		ids = append(ids, r["id"].(int64))
+	}
+	return ids
+}
+
+func TestWhere_AgreesWithRows(t *testing.T) {
+	db := openDB(t)
+	attrs := map[string]any{
+		"user":   map[string]any{"id": "john"},
+		"tenant": "acme",
+	}
+
+	tests := []struct {
+		name     string
+		policies []policy.Policy
+		expected []int64
+	}{
+		{
+			name:     "no policies",
+			policies: nil,
+			expected: []int64{},
+		},
+		{
+			name: "unconditional policy",
+			policies: []policy.Policy{
+				{Subject: "admin", Action: "read", Object: "article"},
+			},
+			expected: []int64{1, 2, 3, 4, 5},
+		},
+		{
+			name: "ownership",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read:own", Object: "article", Conditions: []policy.Condition{
+					{Field: "owner", Op: policy.OpEq, Value: "$user.id"},
+				}},
+			},
+			expected: []int64{1, 3},
+		},
+		{
+			name: "own or published within tenant",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read:own", Object: "article", Conditions: []policy.Condition{
+					{Field: "owner", Op: policy.OpEq, Value: "$user.id"},
+				}},
+				{Subject: "user", Action: "read:shared", Object: "article", Conditions: []policy.Condition{
+					{Field: "status", Op: policy.OpEq, Value: "published"},
+					{Field: "tenant", Op: policy.OpEq, Value: "$tenant"},
+				}},
+			},
+			expected: []int64{1, 3},
+		},
+		{
+			name: "status in list",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+					{Field: "status", Op: policy.OpIn, Value: []string{"draft", "archived"}},
+				}},
+			},
+			expected: []int64{2, 3, 5},
+		},
+		{
+			name: "not equal skips null owners",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+					{Field: "owner", Op: policy.OpNe, Value: "john"},
+				}},
+			},
+			expected: []int64{2, 4},
+		},
+		{
+			name: "null owner",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+					{Field: "owner", Op: policy.OpEq, Value: nil},
+				}},
+			},
+			expected: []int64{5},
+		},
+		{
+			name: "range",
+			policies: []policy.Policy{
+				{Subject: "user", Action: "read", Object: "article", Conditions: []policy.Condition{
+					{Field: "views", Op: policy.OpGte, Value: 3},
+					{Field: "views", Op: policy.OpLt, Value: 50},
+				}},
+			},
+			expected: []int64{1, 3, 5},
+		},
+	}
+
+	b := sqlfilter.New(sqlfilter.Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range tt.policies {
+				require.NoError(t, p.Validate())
+			}
+			g, err := grant.New(tt.policies, false)
+			require.NoError(t, err)
+
+			where, args, err := b.Where(g, attrs)
+			require.NoError(t, err)
+			t.Logf("WHERE %s %v", where, args)
+
+			assert.Equal(t, tt.expected, queryIDs(t, db, where, args))
+			assert.Equal(t, tt.expected, memoryIDs(t, g, attrs))
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package token
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+)
+
+// Verification errors, test with errors.Is
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+	ErrOperation = errors.New("token issued for another operation")
+)
+
+// Operation is the check a token is issued for
+// Verify compares it with the operation of the caller, so a token cannot be
+// replayed for other actions or objects
+type Operation struct {
+	Subjects []string // Compared by Verify when set, in any order
+	Action   string
+	Object   string
+	Tenant   string // Empty for global checks
+}
+
+// Type is the "typ" of the token header
+const Type = "abacl+perm"
+
+// Tokens are header.claims.signature, each part base64url encoded without padding
+var encoding = base64.RawURLEncoding
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// claims is the signed content of a token, with short keys to keep it compact
+type claims struct {
+	Subjects []string      `json:"sub"`
+	Action   string        `json:"act"`
+	Object   string        `json:"obj"`
+	Tenant   string        `json:"ten,omitempty"`
+	Granted  bool          `json:"g"`
+	Strict   bool          `json:"s,omitempty"`
+	Merge    grant.Merge   `json:"m,omitempty"`
+	Policies []tokenPolicy `json:"p,omitempty"`
+	IssuedAt int64         `json:"iat"`
+	Expires  int64         `json:"exp"`
+}
+
+// tokenPolicy holds what a matched policy contributes to the grant,
+// its match constraints were already evaluated by the issuer
+type tokenPolicy struct {
+	Tenant      string            `json:"t,omitempty"`
+	Subject     string            `json:"sub"`
+	Action      string            `json:"act"`
+	Object      string            `json:"obj"`
+	Cascade     bool              `json:"cas,omitempty"`
+	Fields      []string          `json:"f,omitempty"`
+	Filters     []string          `json:"fl,omitempty"`
+	Conditions  []tokenCondition  `json:"c,omitempty"`
+	Obligations []tokenObligation `json:"o,omitempty"`
+}
+
+type tokenCondition struct {
+	Field string          `json:"f"`
+	Op    policy.Operator `json:"op"`
+	Value any             `json:"v,omitempty"`
+}
+
+type tokenObligation struct {
+	Type   string         `json:"t"`
+	Params map[string]any `json:"p,omitempty"`
+	Advice bool           `json:"a,omitempty"`
+}
+
+// Sign encodes the permission of a checked operation into a token valid for ttl
+// Only the matched policies' subjects, actions, objects, fields, filters,
+// conditions and obligations are kept, enough for Field, Filter and Rows
+func Sign(op Operation, perm *permission.Permission, ttl time.Duration, s Signer) (string, error) {
+	if ttl < time.Second {
+		return "", fmt.Errorf("token ttl must be at least one second")
+	}
+	if len(op.Subjects) == 0 || op.Action == "" || op.Object == "" {
+		return "", fmt.Errorf("token operation needs subjects, an action and an object")
+	}
+
+	now := time.Now()
+	c := claims{
+		Subjects: op.Subjects,
+		Action:   op.Action,
+		Object:   op.Object,
+		Tenant:   op.Tenant,
+		Granted:  perm.Granted(),
+		IssuedAt: now.Unix(),
+		Expires:  ceilUnix(now.Add(ttl)),
+	}
+	if g := perm.Grant(); g != nil {
+		opts := g.Options()
+		c.Strict, c.Merge = opts.Strict, opts.Merge
+		for _, p := range g.Policies() {
+			c.Policies = append(c.Policies, fromPolicy(p))
+		}
+	}
+
+	h, err := json.Marshal(header{Alg: s.Alg(), Typ: Type})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode permission: %w", err)
+	}
+
+	signed := encoding.EncodeToString(h) + "." + encoding.EncodeToString(payload)
+	sig, err := s.Sign([]byte(signed))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed + "." + encoding.EncodeToString(sig), nil
+}
+
+// ceilUnix returns t in whole unix seconds, rounded up so tokens never expire early
+func ceilUnix(t time.Time) int64 {
+	sec := t.Unix()
+	if t.Nanosecond() > 0 {
+		sec++
+	}
+	return sec
+}
+
+// Verify checks the signature, expiry and operation of a token and rebuilds its permission
+// The header algorithm must be the verifier's, tokens cannot pick their own
+// A token issued for another action, object, tenant or, when want names
+// them, other subjects fails with ErrOperation
+func Verify(token string, v Verifier, want Operation) (*permission.Permission, error) {
+	return verifyAt(token, v, want, time.Now())
+}
+
+func verifyAt(token string, v Verifier, want Operation, now time.Time) (*permission.Permission, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var h header
+	if err := decode(parts[0], &h); err != nil {
+		return nil, err
+	}
+	if h.Typ != Type {
+		return nil, fmt.Errorf("%w: type %q", ErrMalformed, h.Typ)
+	}
+	if h.Alg != v.Alg() {
+		return nil, fmt.Errorf("%w: algorithm %q, expected %q", ErrSignature, h.Alg, v.Alg())
+	}
+
+	sig, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if !v.Verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrSignature
+	}
+
+	var c claims
+	if err := decode(parts[1], &c); err != nil {
+		return nil, err
+	}
+	if now.Unix() >= c.Expires {
+		return nil, fmt.Errorf("%w at %s", ErrExpired, time.Unix(c.Expires, 0).UTC().Format(time.RFC3339))
+	}
+
+	if err := c.operation(want); err != nil {
+		return nil, err
+	}
+
+	policies := make([]policy.Policy, 0, len(c.Policies))
+	for _, p := range c.Policies {
+		policies = append(policies, p.policy())
+	}
+	g, err := grant.NewWithOptions(policies, grant.Options{Strict: c.Strict, Merge: c.Merge})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grant: %w", err)
+	}
+	return permission.New(c.Granted, g), nil
+}
+
+// operation compares the operation of the token with the expected one
+func (c *claims) operation(want Operation) error {
+	switch {
+	case c.Action != want.Action:
+		return fmt.Errorf("%w: action %q, expected %q", ErrOperation, c.Action, want.Action)
+	case c.Object != want.Object:
+		return fmt.Errorf("%w: object %q, expected %q", ErrOperation, c.Object, want.Object)
+	case c.Tenant != want.Tenant:
+		return fmt.Errorf("%w: tenant %q, expected %q", ErrOperation, c.Tenant, want.Tenant)
+	case want.Subjects != nil && !sameSet(c.Subjects, want.Subjects):
+		return fmt.Errorf("%w: subjects %v, expected %v", ErrOperation, c.Subjects, want.Subjects)
+	}
+	return nil
+}
+
+func sameSet(a, b []string) bool {
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+func decode(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
+
+func fromPolicy(p policy.Policy) tokenPolicy {
+	tp := tokenPolicy{
+		Tenant:  p.Tenant,
+		Subject: p.Subject,
+		Action:  p.Action,
+		Object:  p.Object,
+		Cascade: p.Cascade,
+		Fields:  p.Fields,
+		Filters: p.Filters,
+	}
+	for _, c := range p.Conditions {
+		tp.Conditions = append(tp.Conditions, tokenCondition{Field: c.Field, Op: c.Op, Value: c.Value})
+	}
+	for _, o := range p.Obligations {
+		tp.Obligations = append(tp.Obligations, tokenObligation{Type: o.Type, Params: o.Params, Advice: o.Advice})
+	}
+	return tp
+}
+
+func (tp tokenPolicy) policy() policy.Policy {
+	p := policy.Policy{
+		Tenant:  tp.Tenant,
+		Subject: tp.Subject,
+		Action:  tp.Action,
+		Object:  tp.Object,
+		Cascade: tp.Cascade,
+		Fields:  tp.Fields,
+		Filters: tp.Filters,
+	}
+	for _, c := range tp.Conditions {
+		p.Conditions = append(p.Conditions, policy.Condition{Field: c.Field, Op: c.Op, Value: c.Value})
+	}
+	for _, o := range tp.Obligations {
+		p.Obligations = append(p.Obligations, policy.Obligation{Type: o.Type, Params: o.Params, Advice: o.Advice})
+	}
+	return p
+}
@@ -0,0 +1,223 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/grant"
+	"github.com/alipourhabibi/abacl-go/permission"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checked(t *testing.T, subjects ...string) *permission.Permission {
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Fields: []string{"*", "!password"}, Conditions: []policy.Condition{
+			{Field: "owner", Op: policy.OpEq, Value: "$id"},
+			{Field: "level", Op: policy.OpLte, Value: 2},
+		}},
+		{Subject: "auditor", Action: "read", Object: "article", Fields: []string{"id", "title"},
+			Obligations: []policy.Obligation{{Type: "log", Params: map[string]any{"sink": "compliance"}}}},
+	}, acl.Options{Merge: grant.MergeIntersection}, memory.NewMemoryDriver())
+	require.NoError(t, err)
+
+	perm, err := ac.Check(subjects, "read", "article")
+	require.NoError(t, err)
+	return perm
+}
+
+// read is the operation checked
+func read(subjects ...string) Operation {
+	return Operation{Subjects: subjects, Action: "read", Object: "article"}
+}
+
+func TestSign_RoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	hmacKey := newHMAC(t, "0123456789abcdef0123456789abcdef")
+
+	for _, tc := range []struct {
+		name     string
+		signer   Signer
+		verifier Verifier
+	}{
+		{"hmac", hmacKey, hmacKey},
+		{"ed25519", newSigner(t, priv), newVerifier(t, pub)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			perm := checked(t, "user", "auditor")
+			tok, err := Sign(read("user", "auditor"), perm, time.Minute, tc.signer)
+			require.NoError(t, err)
+
+			got, err := Verify(tok, tc.verifier, read("auditor", "user"))
+			require.NoError(t, err)
+			assert.True(t, got.Granted())
+			assert.Equal(t, perm.Grant().Options(), got.Grant().Options())
+			assert.Equal(t, perm.Obligations(), got.Obligations())
+
+			doc := map[string]any{"id": 1, "title": "Hello", "password": "secret", "body": "..."}
+			want, err := perm.Field(doc)
+			require.NoError(t, err)
+			fields, err := got.Field(doc)
+			require.NoError(t, err)
+			assert.Equal(t, want, fields)
+			assert.Equal(t, map[string]any{"id": 1, "title": "Hello"}, fields)
+
+			rows, err := got.Rows([]map[string]any{
+				{"id": 1, "owner": "alice", "level": 1},
+				{"id": 2, "owner": "bob", "level": 1},
+			}, map[string]any{"id": "alice"})
+			require.NoError(t, err)
+			assert.Len(t, rows, 2) // The auditor policy has no conditions
+		})
+	}
+}
+
+func TestSign_Conditions(t *testing.T) {
+	key := newHMAC(t, "0123456789abcdef0123456789abcdef")
+	tok, err := Sign(read("user"), checked(t, "user"), time.Minute, key)
+	require.NoError(t, err)
+
+	perm, err := Verify(tok, key, read())
+	require.NoError(t, err)
+	rows, err := perm.Rows([]map[string]any{
+		{"id": 1, "owner": "alice", "level": 1},
+		{"id": 2, "owner": "bob", "level": 1},
+		{"id": 3, "owner": "alice", "level": 3},
+	}, map[string]any{"id": "alice"})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0]["id"])
+}
+
+func TestSign_Denied(t *testing.T) {
+	key := newHMAC(t, "0123456789abcdef0123456789abcdef")
+	tok, err := Sign(read("guest"), checked(t, "guest"), time.Minute, key)
+	require.NoError(t, err)
+
+	perm, err := Verify(tok, key, read())
+	require.NoError(t, err)
+	assert.False(t, perm.Granted())
+	fields, err := perm.Field(map[string]any{"id": 1})
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	key := newHMAC(t, "0123456789abcdef0123456789abcdef")
+	tok, err := Sign(read("user"), checked(t, "user"), time.Minute, key)
+	require.NoError(t, err)
+	parts := strings.Split(tok, ".")
+
+	// Widen the fields of the first policy without re-signing
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	forged := strings.Replace(string(payload), `"!password"`, `"password"`, 1)
+	require.NotEqual(t, string(payload), forged)
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(forged)) + "." + parts[2]
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		token    string
+		verifier Verifier
+		err      error
+	}{
+		{"tampered claims", tampered, key, ErrSignature},
+		{"other key", tok, newHMAC(t, "another key of thirty-two bytes!"), ErrSignature},
+		{"other algorithm", tok, newVerifier(t, pub), ErrSignature},
+		{"missing part", parts[0] + "." + parts[1], key, ErrMalformed},
+		{"bad encoding", parts[0] + ".!!." + parts[2], key, ErrSignature},
+		{"bad header", "e30." + parts[1] + "." + parts[2], key, ErrMalformed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Verify(tc.token, tc.verifier, read())
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestVerify_Operation(t *testing.T) {
+	key := newHMAC(t, "0123456789abcdef0123456789abcdef")
+	tok, err := Sign(Operation{Subjects: []string{"user"}, Action: "read", Object: "article", Tenant: "acme"}, checked(t, "user"), time.Minute, key)
+	require.NoError(t, err)
+
+	_, err = Verify(tok, key, Operation{Action: "read", Object: "article", Tenant: "acme"})
+	assert.NoError(t, err)
+	_, err = Verify(tok, key, Operation{Subjects: []string{"user"}, Action: "read", Object: "article", Tenant: "acme"})
+	assert.NoError(t, err)
+
+	// A granted token cannot be replayed for another operation
+	for _, want := range []Operation{
+		{Action: "delete", Object: "article", Tenant: "acme"},
+		{Action: "read", Object: "invoice", Tenant: "acme"},
+		{Action: "read", Object: "article"},
+		{Subjects: []string{"admin"}, Action: "read", Object: "article", Tenant: "acme"},
+	} {
+		_, err = Verify(tok, key, want)
+		assert.ErrorIs(t, err, ErrOperation, want)
+	}
+
+	_, err = Sign(Operation{Action: "read", Object: "article"}, checked(t, "user"), time.Minute, key)
+	assert.ErrorContains(t, err, "needs subjects")
+}
+
+func TestVerify_Expired(t *testing.T) {
+	key := newHMAC(t, "0123456789abcdef0123456789abcdef")
+	tok, err := Sign(read("user"), checked(t, "user"), time.Minute, key)
+	require.NoError(t, err)
+
+	_, err = verifyAt(tok, key, read(), time.Now().Add(59*time.Second))
+	assert.NoError(t, err)
+	_, err = verifyAt(tok, key, read(), time.Now().Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	_, err = Sign(read("user"), checked(t, "user"), 0, key)
+	assert.ErrorContains(t, err, "ttl must be at least one second")
+	_, err = Sign(read("user"), checked(t, "user"), 500*time.Millisecond, key)
+	assert.ErrorContains(t, err, "ttl must be at least one second")
+
+	// Expiry is rounded up, a one second token is valid for at least a second
+	tok, err = Sign(read("user"), checked(t, "user"), time.Second, key)
+	require.NoError(t, err)
+	_, err = verifyAt(tok, key, read(), time.Now().Add(900*time.Millisecond))
+	assert.NoError(t, err)
+}
+
+func TestKeys_Rejected(t *testing.T) {
+	_, err := NewHMAC(nil)
+	assert.Error(t, err)
+	_, err = NewHMAC([]byte("short key"))
+	assert.ErrorContains(t, err, "at least 32 bytes")
+
+	_, err = NewEd25519Signer(ed25519.PrivateKey("not a key"))
+	assert.Error(t, err)
+	_, err = NewEd25519Verifier(nil)
+	assert.Error(t, err)
+}
+
+func newHMAC(t *testing.T, key string) *HMAC {
+	h, err := NewHMAC([]byte(key))
+	require.NoError(t, err)
+	return h
+}
+
+func newSigner(t *testing.T, key ed25519.PrivateKey) *Ed25519Signer {
+	s, err := NewEd25519Signer(key)
+	require.NoError(t, err)
+	return s
+}
+
+func newVerifier(t *testing.T, key ed25519.PublicKey) *Ed25519Verifier {
+	v, err := NewEd25519Verifier(key)
+	require.NoError(t, err)
+	return v
+}
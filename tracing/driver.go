@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/alipourhabibi/abacl-go/driver"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Driver traces the FindContext calls of a driver.Driver as children of the span in their context
+// The other calls take no context, a span of theirs would have no parent, so they
+// are passed through untraced
+type Driver struct {
+	driver.Driver
+	tracer trace.Tracer
+}
+
+// WrapDriver wraps a driver, creating spans with a tracer of tp
+// If d implements driver.Versioned the returned driver does too
+func WrapDriver(d driver.Driver, tp trace.TracerProvider) driver.Driver {
+	w := &Driver{Driver: d, tracer: tp.Tracer(ScopeName)}
+
+	if v, ok := d.(driver.Versioned); ok {
+		return &VersionedDriver{Driver: w, versioned: v}
+	}
+	return w
+}
+
+// FindContext records the span as a child of the span in ctx
+func (d *Driver) FindContext(ctx context.Context, patternPolicy policy.Policy) ([]policy.Policy, error) {
+	ctx, span := d.tracer.Start(ctx, "abacl.driver.Find", trace.WithAttributes(
+		AttrKey.String(patternPolicy.Key()),
+		AttrTenant.String(patternPolicy.Tenant),
+	))
+	defer span.End()
+
+	var (
+		policies []policy.Policy
+		err      error
+	)
+	if f, ok := d.Driver.(driver.ContextFinder); ok {
+		policies, err = f.FindContext(ctx, patternPolicy)
+	} else {
+		policies, err = d.Driver.Find(patternPolicy)
+	}
+	fail(span, err)
+	span.SetAttributes(AttrPolicies.Int(len(policies)))
+	return policies, err
+}
+
+// ListTenant lists through the wrapped driver, see driver.ListTenant
+func (d *Driver) ListTenant(tenant string) []string {
+	return driver.ListTenant(d.Driver, tenant)
+}
+
+// ClearTenant clears through the wrapped driver, see driver.ClearTenant
+func (d *Driver) ClearTenant(tenant string) error {
+	return driver.ClearTenant(d.Driver, tenant)
+}
+
+// ListPage pages through the wrapped driver, see driver.ListPage
+func (d *Driver) ListPage(req driver.PageRequest) (driver.Page[string], error) {
+	return driver.ListPage(d.Driver, req)
+}
+
+// ListTenantPage pages through the wrapped driver, see driver.ListTenantPage
+func (d *Driver) ListTenantPage(tenant string, req driver.PageRequest) (driver.Page[string], error) {
+	return driver.ListTenantPage(d.Driver, tenant, req)
+}
+
+// FindPage pages through the wrapped driver, see driver.FindPage
+func (d *Driver) FindPage(patternPolicy policy.Policy, req driver.PageRequest) (driver.Page[policy.Policy], error) {
+	return driver.FindPage(d.Driver, patternPolicy, req)
+}
+
+// fail marks the span as failed when err is not nil
+func fail(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// VersionedDriver wraps a driver.Versioned, its calls take no context and are passed through
+type VersionedDriver struct {
+	*Driver
+	versioned driver.Versioned
+}
+
+func (d *VersionedDriver) SetAs(author string, p policy.Policy) error {
+	return d.versioned.SetAs(author, p)
+}
+
+func (d *VersionedDriver) DeleteAs(author string, key string) error {
+	return d.versioned.DeleteAs(author, key)
+}
+
+func (d *VersionedDriver) ClearAs(author string) error {
+	return d.versioned.ClearAs(author)
+}
+
+func (d *VersionedDriver) ClearTenantAs(author string, tenant string) error {
+	return d.versioned.ClearTenantAs(author, tenant)
+}
+
+func (d *VersionedDriver) Revisions() []driver.Revision {
+	return d.versioned.Revisions()
+}
+
+func (d *VersionedDriver) Snapshot(rev int64) ([]policy.Policy, error) {
+	return d.versioned.Snapshot(rev)
+}
+
+func (d *VersionedDriver) FindAt(rev int64, patternPolicy policy.Policy) ([]policy.Policy, error) {
+	return d.versioned.FindAt(rev, patternPolicy)
+}
+
+func (d *VersionedDriver) Rollback(author string, rev int64) error {
+	return d.versioned.Rollback(author, rev)
+}
+
+func (d *VersionedDriver) RollbackTenant(author string, tenant string, rev int64) error {
+	return d.versioned.RollbackTenant(author, tenant, rev)
+}
@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/permission"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the created tracers
+const ScopeName = "github.com/alipourhabibi/abacl-go/tracing"
+
+// Span attribute keys
+const (
+	AttrSubjects = attribute.Key("abacl.subjects")
+	AttrAction   = attribute.Key("abacl.action")
+	AttrObject   = attribute.Key("abacl.object")
+	AttrTenant   = attribute.Key("abacl.tenant")
+	AttrGranted  = attribute.Key("abacl.granted")
+	AttrPolicies = attribute.Key("abacl.policies")
+	AttrStrict   = attribute.Key("abacl.strict")
+	AttrKey      = attribute.Key("abacl.key")
+)
+
+// AccessControl traces the checks of an acl.AccessControl
+// Methods other than the checks are those of the wrapped access control
+type AccessControl struct {
+	*acl.AccessControl
+	tracer trace.Tracer
+	tenant string
+}
+
+// New wraps an access control, creating spans with a tracer of tp
+// Wrap its driver with WrapDriver to trace the lookups as children of the checks
+func New(ac *acl.AccessControl, tp trace.TracerProvider) *AccessControl {
+	return &AccessControl{AccessControl: ac, tracer: tp.Tracer(ScopeName)}
+}
+
+// Tenant is like acl.AccessControl.Tenant, the checks of the view are traced too
+func (t *AccessControl) Tenant(name string) *AccessControl {
+	return &AccessControl{AccessControl: t.AccessControl.Tenant(name), tracer: t.tracer, tenant: name}
+}
+
+// As is like acl.AccessControl.As, the checks of the view are traced too
+func (t *AccessControl) As(author string) *AccessControl {
+	return &AccessControl{AccessControl: t.AccessControl.As(author), tracer: t.tracer, tenant: t.tenant}
+}
+
+// Check is like acl.AccessControl.Check but records a span in ctx
+func (t *AccessControl) Check(ctx context.Context, subjects []string, action, object string) (*Permission, error) {
+	return t.CheckWithOptions(ctx, subjects, action, object, t.Options().Strict)
+}
+
+// CheckWithOptions is like acl.AccessControl.CheckWithOptions but records a span in ctx
+func (t *AccessControl) CheckWithOptions(ctx context.Context, subjects []string, action, object string, strict bool) (*Permission, error) {
+	return t.CheckRequest(ctx, acl.Request{
+		Subjects: subjects,
+		Action:   action,
+		Object:   object,
+		Strict:   strict,
+	})
+}
+
+// CheckRequest is like acl.AccessControl.CheckRequest but records a span in ctx
+// The request's own context is replaced by the span's
+func (t *AccessControl) CheckRequest(ctx context.Context, req acl.Request) (*Permission, error) {
+	ctx, span := t.tracer.Start(ctx, "abacl.Check", trace.WithAttributes(
+		AttrSubjects.StringSlice(req.Subjects),
+		AttrAction.String(req.Action),
+		AttrObject.String(req.Object),
+		AttrStrict.Bool(req.Strict),
+		AttrTenant.String(t.tenant),
+	))
+	defer span.End()
+
+	req.Context = ctx
+	perm, err := t.AccessControl.CheckRequest(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		AttrGranted.Bool(perm.Granted()),
+		AttrPolicies.Int(len(perm.Grant().Policies())),
+	)
+	return &Permission{Permission: perm, ctx: ctx, tracer: t.tracer}, nil
+}
+
+// Permission traces the field filtering of a permission.Permission
+// Its spans are children of the check that created it
+type Permission struct {
+	*permission.Permission
+	ctx    context.Context
+	tracer trace.Tracer
+}
+
+func (p *Permission) Field(data any) (map[string]any, error) {
+	return traced(p, "abacl.Field", func() (map[string]any, error) { return p.Permission.Field(data) })
+}
+
+func (p *Permission) Filter(data any) (map[string]any, error) {
+	return traced(p, "abacl.Filter", func() (map[string]any, error) { return p.Permission.Filter(data) })
+}
+
+func (p *Permission) FieldList(data any) ([]map[string]any, error) {
+	return traced(p, "abacl.FieldList", func() ([]map[string]any, error) { return p.Permission.FieldList(data) })
+}
+
+func (p *Permission) FilterList(data any) ([]map[string]any, error) {
+	return traced(p, "abacl.FilterList", func() ([]map[string]any, error) { return p.Permission.FilterList(data) })
+}
+
+func traced[T any](p *Permission, name string, fn func() (T, error)) (T, error) {
+	_, span := p.tracer.Start(p.ctx, name, trace.WithAttributes(
+		AttrGranted.Bool(p.Granted()),
+		AttrPolicies.Int(len(p.Grant().Policies())),
+	))
+	defer span.End()
+
+	out, err := fn()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return out, err
+}
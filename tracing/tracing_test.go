@@ -0,0 +1,166 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alipourhabibi/abacl-go/acl"
+	"github.com/alipourhabibi/abacl-go/driver"
+	"github.com/alipourhabibi/abacl-go/driver/memory"
+	"github.com/alipourhabibi/abacl-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setup(t *testing.T) (*AccessControl, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	drv := WrapDriver(memory.NewMemoryDriver(), tp)
+	_, ok := drv.(driver.Versioned)
+	require.True(t, ok, "versioned drivers stay versioned")
+
+	ac, err := acl.New([]policy.Policy{
+		{Subject: "user", Action: "read", Object: "article", Filters: []string{"*", "!password"}},
+		{Subject: "admin", Action: "read", Object: "article", Filters: []string{"title"}},
+	}, acl.Options{}, drv)
+	require.NoError(t, err)
+
+	exporter.Reset()
+	return New(ac, tp), exporter
+}
+
+func attrs(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	out := make(map[attribute.Key]attribute.Value)
+	for _, kv := range s.Attributes {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func byName(spans tracetest.SpanStubs, name string) []tracetest.SpanStub {
+	var out []tracetest.SpanStub
+	for _, s := range spans {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	ac, exporter := setup(t)
+
+	perm, err := ac.Check(context.Background(), []string{"user", "admin"}, "read", "article")
+	require.NoError(t, err)
+	require.True(t, perm.Granted())
+
+	out, err := perm.Filter(map[string]any{"title": "Hello", "password": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"title": "Hello"}, out)
+
+	spans := exporter.GetSpans()
+	checks := byName(spans, "abacl.Check")
+	require.Len(t, checks, 1)
+	check := checks[0]
+
+	a := attrs(check)
+	assert.Equal(t, []string{"user", "admin"}, a[AttrSubjects].AsStringSlice())
+	assert.Equal(t, "read", a[AttrAction].AsString())
+	assert.Equal(t, "article", a[AttrObject].AsString())
+	assert.True(t, a[AttrGranted].AsBool())
+	assert.Equal(t, int64(2), a[AttrPolicies].AsInt64())
+
+	finds := byName(spans, "abacl.driver.Find")
+	require.Len(t, finds, 2, "one lookup per subject")
+	for _, f := range finds {
+		assert.Equal(t, check.SpanContext.SpanID(), f.Parent.SpanID())
+		assert.Equal(t, int64(1), attrs(f)[AttrPolicies].AsInt64())
+	}
+
+	filters := byName(spans, "abacl.Filter")
+	require.Len(t, filters, 1)
+	assert.Equal(t, check.SpanContext.SpanID(), filters[0].Parent.SpanID())
+}
+
+func TestCheck_Denied(t *testing.T) {
+	ac, exporter := setup(t)
+
+	perm, err := ac.Check(context.Background(), []string{"guest"}, "read", "article")
+	require.NoError(t, err)
+	assert.False(t, perm.Granted())
+
+	check := byName(exporter.GetSpans(), "abacl.Check")[0]
+	assert.False(t, attrs(check)[AttrGranted].AsBool())
+	assert.Equal(t, int64(0), attrs(check)[AttrPolicies].AsInt64())
+}
+
+func TestCheck_Error(t *testing.T) {
+	ac, exporter := setup(t)
+
+	_, err := ac.Check(context.Background(), nil, "read", "article")
+	require.Error(t, err)
+
+	check := byName(exporter.GetSpans(), "abacl.Check")[0]
+	assert.Equal(t, "Error", check.Status.Code.String())
+}
+
+func TestTenant(t *testing.T) {
+	ac, exporter := setup(t)
+
+	view := ac.Tenant("acme")
+	perm, err := view.Check(context.Background(), []string{"user"}, "read", "article")
+	require.NoError(t, err)
+	assert.True(t, perm.Granted(), "tenants inherit the global policies")
+
+	checks := byName(exporter.GetSpans(), "abacl.Check")
+	require.Len(t, checks, 1)
+	assert.Equal(t, "acme", attrs(checks[0])[AttrTenant].AsString())
+
+	finds := byName(exporter.GetSpans(), "abacl.driver.Find")
+	require.Len(t, finds, 2, "tenant and global lookups")
+	for _, f := range finds {
+		assert.Equal(t, checks[0].SpanContext.SpanID(), f.Parent.SpanID())
+	}
+}
+
+func TestDriver(t *testing.T) {
+	ac, exporter := setup(t)
+
+	// Calls without a context record no span rather than orphan root spans
+	p := policy.Policy{Subject: "user", Action: "write", Object: "article"}
+	require.NoError(t, ac.As("alice").Add(p))
+
+	all, err := ac.ListAll()
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	page, err := ac.ListPage(driver.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.Next)
+
+	revs, err := ac.Revisions()
+	require.NoError(t, err)
+	assert.Len(t, revs, 3)
+	assert.Equal(t, "alice", revs[2].Author)
+
+	assert.Empty(t, exporter.GetSpans())
+}
+
+func TestDriver_FindPage(t *testing.T) {
+	mem := memory.NewMemoryDriver()
+	require.NoError(t, mem.Set(policy.Policy{Subject: "user", Action: "read", Object: "article"}))
+	require.NoError(t, mem.Set(policy.Policy{Subject: "user", Action: "write", Object: "article"}))
+
+	pager, ok := WrapDriver(mem, sdktrace.NewTracerProvider()).(driver.Paginator)
+	require.True(t, ok, "wrapped drivers page through their policies")
+	found, err := pager.FindPage(policy.Policy{Subject: "user", Action: ".*", Object: "article"}, driver.PageRequest{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, found.Items, 1)
+	assert.NotEmpty(t, found.Next)
+}